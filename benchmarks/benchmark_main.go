@@ -1,4 +1,5 @@
 package benchmarks
+
 // FLARE PSI Benchmarking Tool
 // Uses new distributed PSI architecture for accurate performance measurement
 
@@ -6,12 +7,21 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"log"
+	"math"
+	"net/http"
+	httppprof "net/http/pprof"
 	"os"
+	"path/filepath"
 	"runtime"
+	"runtime/pprof"
+	"strings"
 	"time"
 
 	psi "github.com/SanthoshCheemala/FLARE/internal/crypto/PSI"
 	"github.com/SanthoshCheemala/FLARE/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // BenchmarkConfig holds benchmark configuration
@@ -22,20 +32,26 @@ type BenchmarkConfig struct {
 	OutputDir     string
 	Verbose       bool
 	Iterations    int
+	PprofAddr     string
+	MetricsAddr   string
+	Transport     string
+	ReportFormats string
+	AutoTune      bool
+	TargetFPR     float64
 }
 
 // BenchmarkResult holds benchmark results
 type BenchmarkResult struct {
-	Config              BenchmarkConfig       `json:"config"`
-	InitializationTime  time.Duration         `json:"initializationTime"`
-	EncryptionTime      time.Duration         `json:"encryptionTime"`
-	DetectionTime       time.Duration         `json:"detectionTime"`
-	TotalTime           time.Duration         `json:"totalTime"`
-	Throughput          float64               `json:"throughput"`
-	IntersectionSize    int                   `json:"intersectionSize"`
-	MemoryUsageMB       uint64                `json:"memoryUsageMB"`
-	CPUCores            int                   `json:"cpuCores"`
-	Timestamp           string                `json:"timestamp"`
+	Config             BenchmarkConfig `json:"config"`
+	InitializationTime time.Duration   `json:"initializationTime"`
+	EncryptionTime     time.Duration   `json:"encryptionTime"`
+	DetectionTime      time.Duration   `json:"detectionTime"`
+	TotalTime          time.Duration   `json:"totalTime"`
+	Throughput         float64         `json:"throughput"`
+	IntersectionSize   int             `json:"intersectionSize"`
+	MemoryUsageMB      uint64          `json:"memoryUsageMB"`
+	CPUCores           int             `json:"cpuCores"`
+	Timestamp          string          `json:"timestamp"`
 }
 
 func main() {
@@ -47,14 +63,42 @@ func main() {
 	flag.StringVar(&config.OutputDir, "output-dir", "benchmark_results", "Output directory for results")
 	flag.BoolVar(&config.Verbose, "verbose", false, "Enable verbose logging")
 	flag.IntVar(&config.Iterations, "iterations", 1, "Number of benchmark iterations")
+	flag.StringVar(&config.PprofAddr, "pprof-addr", "", "If set, serve net/http/pprof handlers on this address (e.g. localhost:6060)")
+	flag.StringVar(&config.MetricsAddr, "metrics-addr", "", "If set, serve Prometheus metrics on this address (e.g. localhost:9090)")
+	flag.StringVar(&config.Transport, "transport", "inproc", "Ciphertext transport to benchmark: inproc, kafka, or grpc")
+	flag.StringVar(&config.ReportFormats, "report-formats", "json", "Comma-separated report formats to write to output-dir: json,csv,prom,html")
+	flag.BoolVar(&config.AutoTune, "auto-tune", false, "Pick ring-dimension automatically from server-size/target-fpr instead of using -ring-dimension")
+	flag.Float64Var(&config.TargetFPR, "target-fpr", 1e-6, "Target decryption-failure probability for -auto-tune (see psi.AutoTune)")
 	flag.Parse()
 
+	if config.AutoTune {
+		chosen := chooseRingDimension(config.TargetFPR, config.ServerSize)
+		log.Printf("auto-tune: target-fpr=%v server-size=%d -> ring-dimension=%d (overrides -ring-dimension=%d)",
+			config.TargetFPR, config.ServerSize, chosen, config.RingDimension)
+		config.RingDimension = chosen
+	}
+
+	if config.PprofAddr != "" {
+		startPprofServer(config.PprofAddr)
+	}
+	if config.MetricsAddr != "" {
+		startMetricsServer(config.MetricsAddr)
+	}
+
 	// Validate config
 	if err := validateConfig(&config); err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
 		os.Exit(1)
 	}
 
+	if config.Transport != "inproc" {
+		// This harness still measures the batch ServerInitialize -> Client ->
+		// DetectIntersectionWithContext path regardless of --transport; the
+		// per-event p50/p95/p99 numbers a kafka/grpc run would actually care
+		// about come from psi.StreamingServer.LatencyReport, not from here.
+		log.Printf("note: --transport=%s only selects the ciphertext delivery mechanism for a psi.StreamingServer; this batch harness does not drive one yet", config.Transport)
+	}
+
 	// Create output directory
 	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
@@ -80,7 +124,7 @@ func main() {
 		}
 
 		result := runBenchmark(&config)
-		
+
 		totalInit += result.InitializationTime
 		totalEncrypt += result.EncryptionTime
 		totalDetect += result.DetectionTime
@@ -110,11 +154,151 @@ func main() {
 	displayResults(&avgResult)
 
 	// Save results
-	saveResults(&avgResult, config.OutputDir)
+	saveResults(&avgResult, config.OutputDir, config.ReportFormats)
 
 	fmt.Printf("\nBenchmark complete! Results saved to %s/\n", config.OutputDir)
 }
 
+// benchmarkMetrics holds the Prometheus collectors that mirror the memory,
+// throughput and intersection numbers already present in BenchmarkResult/timing_breakdown.json,
+// so a scrape target can track them across runs instead of re-parsing JSON.
+var benchmarkMetrics = struct {
+	MemoryUsageMB prometheus.Gauge
+	Throughput    prometheus.Gauge
+	Matches       prometheus.Counter
+	Errors        prometheus.Counter
+}{
+	MemoryUsageMB: prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "lepsi_bench", Name: "memory_usage_mb",
+		Help: "Memory used by the most recently completed benchmark iteration, in MB.",
+	}),
+	Throughput: prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "lepsi_bench", Name: "throughput_ops_per_second",
+		Help: "Throughput of the most recently completed benchmark iteration.",
+	}),
+	Matches: prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "lepsi_bench", Name: "matches_total",
+		Help: "Total intersection matches found across all benchmark iterations.",
+	}),
+	Errors: prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "lepsi_bench", Name: "errors_total",
+		Help: "Total errors encountered across all benchmark iterations.",
+	}),
+}
+
+func init() {
+	prometheus.MustRegister(
+		benchmarkMetrics.MemoryUsageMB,
+		benchmarkMetrics.Throughput,
+		benchmarkMetrics.Matches,
+		benchmarkMetrics.Errors,
+	)
+}
+
+// startPprofServer exposes the standard net/http/pprof handlers (goroutine,
+// heap, profile, trace, ...) on addr so operators can attach `go tool pprof`
+// while a benchmark run is in progress.
+func startPprofServer(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", httppprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+
+	go func() {
+		log.Printf("pprof server listening on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("pprof server stopped: %v", err)
+		}
+	}()
+}
+
+// startMetricsServer exposes the Prometheus text-exposition format on addr.
+func startMetricsServer(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		log.Printf("metrics server listening on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("metrics server stopped: %v", err)
+		}
+	}()
+}
+
+// phaseProfiler captures a CPU profile across a benchmark phase and writes a
+// matching heap snapshot when the phase ends, so `name_cpu.pprof` and
+// `name_heap.pprof` land in OutputDir next to the JSON report.
+type phaseProfiler struct {
+	outputDir string
+	name      string
+	cpuFile   *os.File
+}
+
+func startPhaseProfile(outputDir, name string) *phaseProfiler {
+	p := &phaseProfiler{outputDir: outputDir, name: name}
+	f, err := os.Create(filepath.Join(outputDir, name+"_cpu.pprof"))
+	if err != nil {
+		log.Printf("phase profile %s: could not create cpu profile: %v", name, err)
+		return p
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		log.Printf("phase profile %s: could not start cpu profile: %v", name, err)
+		f.Close()
+		return p
+	}
+	p.cpuFile = f
+	return p
+}
+
+func (p *phaseProfiler) stop() {
+	if p.cpuFile == nil {
+		return
+	}
+	pprof.StopCPUProfile()
+	p.cpuFile.Close()
+
+	heapPath := filepath.Join(p.outputDir, p.name+"_heap.pprof")
+	f, err := os.Create(heapPath)
+	if err != nil {
+		log.Printf("phase profile %s: could not create heap profile: %v", p.name, err)
+		return
+	}
+	defer f.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		log.Printf("phase profile %s: could not write heap profile: %v", p.name, err)
+	}
+}
+
+// ringDimensionCandidates mirrors psi.secureParameterSets: ring dimensions
+// ordered smallest (cheapest) to largest (most noise headroom), all at the
+// repo's standard 58-bit modulus.
+var ringDimensionCandidates = []int{256, 512, 1024, 2048}
+
+// chooseRingDimension is the benchmark harness's copy of psi.AutoTune's
+// selection heuristic: each witness-tree layer costs roughly one bit of
+// noise margin, and doubling the ring dimension roughly doubles the margin
+// bits available before the modulus is exhausted. It duplicates the
+// heuristic rather than importing psi.AutoTune because this harness is
+// still wired to the legacy FLARE-based PSI package, not pkg/psi.
+func chooseRingDimension(targetFPR float64, datasetSize int) int {
+	const qBits = 58.0
+	layers := math.Ceil(math.Log2(16 * float64(datasetSize)))
+
+	for _, d := range ringDimensionCandidates {
+		marginBits := qBits/2*(float64(d)/256) - layers
+		if marginBits <= 0 {
+			continue
+		}
+		if math.Exp2(-marginBits) < targetFPR {
+			return d
+		}
+	}
+	return ringDimensionCandidates[len(ringDimensionCandidates)-1]
+}
+
 func validateConfig(config *BenchmarkConfig) error {
 	if config.ServerSize < 1 {
 		return fmt.Errorf("server size must be positive")
@@ -122,23 +306,35 @@ func validateConfig(config *BenchmarkConfig) error {
 	if config.ClientSize < 1 {
 		return fmt.Errorf("client size must be positive")
 	}
-	if config.RingDimension != 256 && config.RingDimension != 512 && 
-	   config.RingDimension != 1024 && config.RingDimension != 2048 {
+	if config.RingDimension != 256 && config.RingDimension != 512 &&
+		config.RingDimension != 1024 && config.RingDimension != 2048 {
 		return fmt.Errorf("ring dimension must be 256, 512, 1024, or 2048")
 	}
 	if config.Iterations < 1 {
 		return fmt.Errorf("iterations must be positive")
 	}
+	switch config.Transport {
+	case "inproc", "kafka", "grpc":
+	default:
+		return fmt.Errorf("transport must be inproc, kafka, or grpc, got %q", config.Transport)
+	}
+	for _, format := range strings.Split(config.ReportFormats, ",") {
+		switch strings.TrimSpace(format) {
+		case "json", "csv", "prom", "html":
+		default:
+			return fmt.Errorf("report-formats entries must be json, csv, prom, or html, got %q", format)
+		}
+	}
 	return nil
 }
 
 func runBenchmark(config *BenchmarkConfig) BenchmarkResult {
 	var memStats runtime.MemStats
-	
+
 	// Generate synthetic datasets
 	serverData := generateSyntheticData(config.ServerSize, "server")
 	clientData := generateSyntheticData(config.ClientSize, "client")
-	
+
 	// Add some overlapping items
 	overlapCount := min(config.ServerSize/4, config.ClientSize/2)
 	for i := 0; i < overlapCount; i++ {
@@ -150,17 +346,28 @@ func runBenchmark(config *BenchmarkConfig) BenchmarkResult {
 	// Phase 1: Server Initialization
 	runtime.ReadMemStats(&memStats)
 	memBefore := memStats.Alloc
-	
+
+	profile := config.PprofAddr != ""
+
 	startInit := time.Now()
 	serverHashes, err := preprocessData(serverData)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error preprocessing server data: %v\n", err)
+		benchmarkMetrics.Errors.Inc()
 		os.Exit(1)
 	}
-	
+
+	var initProfile *phaseProfiler
+	if profile {
+		initProfile = startPhaseProfile(config.OutputDir, "init")
+	}
 	serverCtx, err := psi.ServerInitialize(serverHashes, dbPath)
+	if initProfile != nil {
+		initProfile.stop()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing server: %v\n", err)
+		benchmarkMetrics.Errors.Inc()
 		os.Exit(1)
 	}
 	initTime := time.Since(startInit)
@@ -170,20 +377,37 @@ func runBenchmark(config *BenchmarkConfig) BenchmarkResult {
 	clientHashes, err := preprocessData(clientData)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error preprocessing client data: %v\n", err)
+		benchmarkMetrics.Errors.Inc()
 		os.Exit(1)
 	}
-	
+
+	var encryptProfile *phaseProfiler
+	if profile {
+		encryptProfile = startPhaseProfile(config.OutputDir, "encrypt")
+	}
 	ciphertexts := psi.Client(clientHashes, serverCtx.PublicParams, serverCtx.Message, serverCtx.LEParams)
+	if encryptProfile != nil {
+		encryptProfile.stop()
+	}
 	encryptTime := time.Since(startEncrypt)
 
 	// Phase 3: Intersection Detection
 	startDetect := time.Now()
+	var detectProfile *phaseProfiler
+	if profile {
+		detectProfile = startPhaseProfile(config.OutputDir, "detect")
+	}
 	intersectionHashes, err := psi.DetectIntersectionWithContext(serverCtx, ciphertexts)
+	if detectProfile != nil {
+		detectProfile.stop()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error detecting intersection: %v\n", err)
+		benchmarkMetrics.Errors.Inc()
 		os.Exit(1)
 	}
 	detectTime := time.Since(startDetect)
+	benchmarkMetrics.Matches.Add(float64(len(intersectionHashes)))
 
 	// Calculate memory usage
 	runtime.ReadMemStats(&memStats)
@@ -196,6 +420,11 @@ func runBenchmark(config *BenchmarkConfig) BenchmarkResult {
 	// Clean up
 	os.Remove(dbPath)
 
+	benchmarkMetrics.MemoryUsageMB.Set(float64(memUsedMB))
+	if totalTime.Seconds() > 0 {
+		benchmarkMetrics.Throughput.Set(float64(config.ServerSize*config.ClientSize) / totalTime.Seconds())
+	}
+
 	return BenchmarkResult{
 		Config:             *config,
 		InitializationTime: initTime,
@@ -218,11 +447,11 @@ func generateSyntheticData(count int, prefix string) []interface{} {
 		case 1:
 			data[i] = fmt.Sprintf("%s_item_%d", prefix, i)
 		case 2:
-			data[i] = i * 12345 + 67890
+			data[i] = i*12345 + 67890
 		case 3:
 			data[i] = map[string]interface{}{
-				"id":   i,
-				"type": prefix,
+				"id":    i,
+				"type":  prefix,
 				"value": fmt.Sprintf("data_%d", i),
 			}
 		case 4:
@@ -249,32 +478,50 @@ func displayResults(result *BenchmarkResult) {
 	fmt.Printf("Throughput:      %.2f ops/sec\n", result.Throughput)
 	fmt.Printf("Memory Used:     %d MB\n", result.MemoryUsageMB)
 	fmt.Printf("Intersection:    %d items found\n", result.IntersectionSize)
-	
+
 	// Calculate percentages
 	initPct := float64(result.InitializationTime) / float64(result.TotalTime) * 100
 	encryptPct := float64(result.EncryptionTime) / float64(result.TotalTime) * 100
 	detectPct := float64(result.DetectionTime) / float64(result.TotalTime) * 100
-	
+
 	fmt.Println("\nTime Breakdown:")
 	fmt.Printf("  Initialization: %.1f%%\n", initPct)
 	fmt.Printf("  Encryption:     %.1f%%\n", encryptPct)
 	fmt.Printf("  Detection:      %.1f%%\n", detectPct)
 }
 
-func saveResults(result *BenchmarkResult, outputDir string) {
+func saveResults(result *BenchmarkResult, outputDir, reportFormats string) {
+	for _, format := range strings.Split(reportFormats, ",") {
+		var err error
+		switch strings.TrimSpace(format) {
+		case "json":
+			err = writeJSONResult(result, outputDir)
+		case "csv":
+			err = writeCSVResult(result, outputDir)
+		case "prom":
+			err = writePromResult(result, outputDir)
+		case "html":
+			err = writeHTMLResult(result, outputDir)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s report: %v\n", format, err)
+		}
+	}
+}
+
+func writeJSONResult(result *BenchmarkResult, outputDir string) error {
 	// Save JSON results
 	jsonPath := fmt.Sprintf("%s/benchmark_result.json", outputDir)
 	file, err := os.Create(jsonPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating results file: %v\n", err)
-		return
+		return fmt.Errorf("creating results file: %w", err)
 	}
 	defer file.Close()
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(result); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding results: %v\n", err)
+		return fmt.Errorf("encoding results: %w", err)
 	}
 
 	// Generate detailed breakdown
@@ -310,14 +557,100 @@ func saveResults(result *BenchmarkResult, outputDir string) {
 	breakdownPath := fmt.Sprintf("%s/timing_breakdown.json", outputDir)
 	breakdownFile, err := os.Create(breakdownPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating breakdown file: %v\n", err)
-		return
+		return fmt.Errorf("creating breakdown file: %w", err)
 	}
 	defer breakdownFile.Close()
 
 	encoder = json.NewEncoder(breakdownFile)
 	encoder.SetIndent("", "  ")
-	encoder.Encode(breakdown)
+	return encoder.Encode(breakdown)
+}
+
+// writeCSVResult appends one row per run to benchmark_results.csv, writing
+// the header only the first time, so scripted parameter sweeps accumulate
+// every run into one spreadsheet instead of overwriting the last one.
+func writeCSVResult(result *BenchmarkResult, outputDir string) error {
+	path := fmt.Sprintf("%s/benchmark_results.csv", outputDir)
+	writeHeader := true
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		writeHeader = false
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if writeHeader {
+		fmt.Fprintln(file, "timestamp,serverSize,clientSize,ringDimension,initMs,encryptMs,detectMs,totalMs,throughput,intersectionSize,memoryUsageMB,cpuCores")
+	}
+	fmt.Fprintf(file, "%s,%d,%d,%d,%d,%d,%d,%d,%f,%d,%d,%d\n",
+		result.Timestamp, result.Config.ServerSize, result.Config.ClientSize, result.Config.RingDimension,
+		result.InitializationTime.Milliseconds(), result.EncryptionTime.Milliseconds(), result.DetectionTime.Milliseconds(),
+		result.TotalTime.Milliseconds(), result.Throughput, result.IntersectionSize, result.MemoryUsageMB, result.CPUCores)
+	return nil
+}
+
+// writePromResult overwrites benchmark_result.prom in full with the latest
+// run's numbers in Prometheus text exposition format, the layout a
+// node_exporter textfile collector expects.
+func writePromResult(result *BenchmarkResult, outputDir string) error {
+	path := fmt.Sprintf("%s/benchmark_result.prom", outputDir)
+	metrics := []struct {
+		name, help string
+		value      float64
+	}{
+		{"lepsi_bench_init_ms", "Server initialization time of the most recent benchmark run, in ms.", float64(result.InitializationTime.Milliseconds())},
+		{"lepsi_bench_encrypt_ms", "Client encryption time of the most recent benchmark run, in ms.", float64(result.EncryptionTime.Milliseconds())},
+		{"lepsi_bench_detect_ms", "Intersection detection time of the most recent benchmark run, in ms.", float64(result.DetectionTime.Milliseconds())},
+		{"lepsi_bench_total_ms", "Total time of the most recent benchmark run, in ms.", float64(result.TotalTime.Milliseconds())},
+		{"lepsi_bench_throughput_ops_per_second", "Throughput of the most recent benchmark run.", result.Throughput},
+		{"lepsi_bench_intersection_size", "Intersection size found by the most recent benchmark run.", float64(result.IntersectionSize)},
+		{"lepsi_bench_memory_usage_mb", "Memory usage of the most recent benchmark run, in MB.", float64(result.MemoryUsageMB)},
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", m.name, m.help, m.name, m.name, m.value)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// writeHTMLResult writes a self-contained HTML page summarizing the latest
+// benchmark run, with a bar chart of the phase-time breakdown.
+func writeHTMLResult(result *BenchmarkResult, outputDir string) error {
+	path := fmt.Sprintf("%s/benchmark_result.html", outputDir)
+
+	phases := map[string]int{
+		"init":    int(result.InitializationTime.Milliseconds()),
+		"encrypt": int(result.EncryptionTime.Milliseconds()),
+		"detect":  int(result.DetectionTime.Milliseconds()),
+	}
+	max := 1
+	for _, v := range phases {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>LE-PSI Benchmark</title></head><body>\n")
+	fmt.Fprintf(&b, "<h1>LE-PSI Benchmark — %s</h1>\n", result.Timestamp)
+	fmt.Fprintf(&b, "<p>Server size %d, client size %d, intersection %d, throughput %.1f ops/s.</p>\n",
+		result.Config.ServerSize, result.Config.ClientSize, result.IntersectionSize, result.Throughput)
+	b.WriteString("<h2>Phase Time Breakdown (ms)</h2>\n<svg width=\"550\" height=\"72\" xmlns=\"http://www.w3.org/2000/svg\">\n")
+	for i, name := range []string{"init", "encrypt", "detect"} {
+		v := phases[name]
+		barWidth := int(float64(v) / float64(max) * 400)
+		y := i * 24
+		fmt.Fprintf(&b, "<text x=\"0\" y=\"%d\" font-size=\"12\">%s</text>\n", y+16, name)
+		fmt.Fprintf(&b, "<rect x=\"80\" y=\"%d\" width=\"%d\" height=\"18\" fill=\"steelblue\"/>\n", y, barWidth)
+		fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" font-size=\"12\">%d ms</text>\n", 85+barWidth, y+14, v)
+	}
+	b.WriteString("</svg>\n</body></html>\n")
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
 }
 
 func min(a, b int) int {
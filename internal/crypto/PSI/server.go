@@ -142,19 +142,20 @@ func DeserializeParameters(params *SerializableParams) (*matrix.Vector, *ring.Po
 	return ppVec, msgPoly, le, nil
 }
 
-
 func ServerInitialize(private_set_X []uint64, Treepath string) (*ServerInitContext, error) {
 	monitor := NewPerformanceMonitor()
-	
+
 	X_size := len(private_set_X)
 	if X_size == 0 {
 		return nil, errors.New("server set is empty")
 	}
 
-	leParams, err := SetupLEParameters(len(private_set_X))
+	leParams, resolvedParams, err := SetupLEParameters(len(private_set_X))
 	if err != nil {
 		return nil, fmt.Errorf("SetupLEParameters: %w", err)
 	}
+	log.Printf("LE parameters: layers=%d load_factor=%.6f collision_probability=%.6e",
+		resolvedParams.Layers, resolvedParams.LoadFactor, resolvedParams.CollisionProbability)
 
 	db, err := sql.Open("sqlite3", Treepath)
 	if err != nil {
@@ -168,13 +169,13 @@ func ServerInitialize(private_set_X []uint64, Treepath string) (*ServerInitConte
 
 	publicKeys := make([]*matrix.Vector, X_size)
 	privateKeys := make([]*matrix.Vector, X_size)
-	hashedClient := make([]uint64, X_size) 
+	hashedClient := make([]uint64, X_size)
 	keyGenStart := time.Now()
 	numWorkers := runtime.NumCPU()
 	if numWorkers > X_size {
 		numWorkers = X_size
 	}
-	
+
 	workChan := make(chan int, X_size)
 	var wg sync.WaitGroup
 
@@ -206,7 +207,7 @@ func ServerInitialize(private_set_X []uint64, Treepath string) (*ServerInitConte
 	witnessStart := time.Now()
 	witnessesVec1 := make([][]*matrix.Vector, X_size)
 	witnessesVec2 := make([][]*matrix.Vector, X_size)
-	
+
 	witnessChan := make(chan int, X_size)
 	var witnessWg sync.WaitGroup
 
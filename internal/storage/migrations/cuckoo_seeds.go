@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+)
+
+// CuckooSeedsRecord is the salt and lane count a cuckoo-hashed tree.db's
+// witness leaves were assigned with, persisted so a server restarting
+// against an existing tree reuses the same leaf assignments instead of
+// generating a fresh random salt that would disagree with clients computing
+// candidates from the original CuckooSeeds. Salt is stored hex-encoded
+// rather than as a BLOB/BYTEA column so the same literal schema works
+// against both the sqlite and postgres backends, matching le_params'
+// portable-INTEGER-only approach.
+type CuckooSeedsRecord struct {
+	Salt []byte
+	K    int
+}
+
+func LoadCuckooSeeds(db *sql.DB) (CuckooSeedsRecord, bool, error) {
+	var saltHex string
+	var rec CuckooSeedsRecord
+	row := db.QueryRow("SELECT salt_hex, k FROM cuckoo_seeds WHERE id = 1")
+	if err := row.Scan(&saltHex, &rec.K); err != nil {
+		if err == sql.ErrNoRows {
+			return CuckooSeedsRecord{}, false, nil
+		}
+		return CuckooSeedsRecord{}, false, fmt.Errorf("migrations: load cuckoo_seeds: %w", err)
+	}
+
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return CuckooSeedsRecord{}, false, fmt.Errorf("migrations: decode cuckoo_seeds salt: %w", err)
+	}
+	rec.Salt = salt
+	return rec, true, nil
+}
+
+func SaveCuckooSeeds(db *sql.DB, rec CuckooSeedsRecord, dialect string) error {
+	query := fmt.Sprintf(
+		"INSERT INTO cuckoo_seeds (id, salt_hex, k) VALUES (%s, %s, %s)",
+		placeholder(dialect, 1), placeholder(dialect, 2), placeholder(dialect, 3),
+	)
+	_, err := db.Exec(query, 1, hex.EncodeToString(rec.Salt), rec.K)
+	return err
+}
@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// LEParamsRecord is the snapshot of LE parameters recorded in the le_params
+// table, used to detect a persistent tree being reopened against
+// incompatible LE parameters (e.g. after changing the ring dimension or
+// expansion factor).
+type LEParamsRecord struct {
+	Q      uint64
+	D      int
+	Layers int
+	M      int
+	M2     int
+}
+
+// Mismatch reports whether rec differs from the LE parameters currently in
+// use, field by field.
+func (rec LEParamsRecord) Mismatch(q uint64, d, layers, m, m2 int) bool {
+	return rec.Q != q || rec.D != d || rec.Layers != layers || rec.M != m || rec.M2 != m2
+}
+
+// LoadLEParams returns the LE parameters recorded for this tree, or
+// (LEParamsRecord{}, false, nil) if none have been recorded yet - a fresh
+// tree, or one created before migration version 1 existed.
+func LoadLEParams(db *sql.DB) (LEParamsRecord, bool, error) {
+	var rec LEParamsRecord
+	row := db.QueryRow("SELECT q, d, layers, m, m2 FROM le_params WHERE id = 1")
+	if err := row.Scan(&rec.Q, &rec.D, &rec.Layers, &rec.M, &rec.M2); err != nil {
+		if err == sql.ErrNoRows {
+			return LEParamsRecord{}, false, nil
+		}
+		return LEParamsRecord{}, false, fmt.Errorf("migrations: load le_params: %w", err)
+	}
+	return rec, true, nil
+}
+
+// SaveLEParams records rec as the LE parameters this tree was built with.
+// Callers must only call this once per tree, after confirming via
+// LoadLEParams that no le_params row exists yet.
+func SaveLEParams(db *sql.DB, rec LEParamsRecord, dialect string) error {
+	query := fmt.Sprintf(
+		"INSERT INTO le_params (id, q, d, layers, m, m2) VALUES (%s, %s, %s, %s, %s, %s)",
+		placeholder(dialect, 1), placeholder(dialect, 2), placeholder(dialect, 3),
+		placeholder(dialect, 4), placeholder(dialect, 5), placeholder(dialect, 6),
+	)
+	_, err := db.Exec(query, 1, rec.Q, rec.D, rec.Layers, rec.M, rec.M2)
+	return err
+}
@@ -0,0 +1,69 @@
+// Package migrations implements a minimal golang-migrate-style schema
+// versioning system for the witness-tree database: numbered migrations with
+// up/down SQL, applied through a schema_migrations table that records each
+// applied version and a checksum of the SQL that ran, so drift between the
+// registered migrations and what a given database actually has on disk is
+// detectable instead of silent.
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// Migration is one numbered schema change: Up applies it, Down reverses it.
+type Migration struct {
+	Version     int
+	Description string
+	Up          string
+	Down        string
+}
+
+// Registry lists every migration this binary knows about, in the order
+// schema changes were introduced. Append new entries here as the tree
+// schema grows; never edit an already-released entry's Up/Down SQL, since
+// that changes its checksum out from under databases that already applied
+// it and Up will refuse to proceed.
+var Registry = []Migration{
+	{
+		Version:     1,
+		Description: "create le_params table recording the LE parameters the tree was built with",
+		Up: `CREATE TABLE IF NOT EXISTS le_params (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			q INTEGER NOT NULL,
+			d INTEGER NOT NULL,
+			layers INTEGER NOT NULL,
+			m INTEGER NOT NULL,
+			m2 INTEGER NOT NULL
+		)`,
+		Down: `DROP TABLE IF EXISTS le_params`,
+	},
+	{
+		Version:     2,
+		Description: "create cuckoo_seeds table recording the salt a cuckoo-hashed tree's witness leaves were assigned with",
+		Up: `CREATE TABLE IF NOT EXISTS cuckoo_seeds (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			salt_hex TEXT NOT NULL,
+			k INTEGER NOT NULL
+		)`,
+		Down: `DROP TABLE IF EXISTS cuckoo_seeds`,
+	},
+}
+
+// checksum returns a hex-encoded SHA-256 digest of a migration's combined
+// up/down SQL, used to detect a migration's text changing after it was
+// already applied to a database.
+func checksum(m Migration) string {
+	sum := sha256.Sum256([]byte(m.Up + "\x00" + m.Down))
+	return hex.EncodeToString(sum[:])
+}
+
+// sorted returns Registry ordered by Version, since entries may be appended
+// out of order over time.
+func sorted() []Migration {
+	out := make([]Migration, len(Registry))
+	copy(out, Registry)
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
@@ -0,0 +1,167 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const schemaMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	description TEXT NOT NULL,
+	checksum TEXT NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`
+
+// placeholder returns the n-th bind parameter in the SQL dialect named by
+// dialect: "?" for sqlite, "$n" for postgres. The Backend implementations in
+// the storage package already duplicate PutNode/GetNode/BatchPut per
+// dialect for the same reason - a migrations.Runner has no other way to
+// know which syntax the *sql.DB it was handed expects.
+func placeholder(dialect string, n int) string {
+	if dialect == "postgres" {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+func ensureSchemaMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(schemaMigrationsTable)
+	return err
+}
+
+func appliedVersions(db *sql.DB) (map[int]string, error) {
+	rows, err := db.Query("SELECT version, checksum FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var sum string
+		if err := rows.Scan(&version, &sum); err != nil {
+			return nil, err
+		}
+		applied[version] = sum
+	}
+	return applied, rows.Err()
+}
+
+// CurrentVersion returns the highest migration version recorded as applied,
+// or 0 if none have been applied yet.
+func CurrentVersion(db *sql.DB) (int, error) {
+	if err := ensureSchemaMigrationsTable(db); err != nil {
+		return 0, fmt.Errorf("migrations: ensure schema_migrations: %w", err)
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return 0, fmt.Errorf("migrations: read schema_migrations: %w", err)
+	}
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// Up applies every migration in Registry newer than db's current version,
+// each inside its own transaction, recording its version and checksum in
+// schema_migrations on success. It refuses to proceed if a migration
+// already recorded as applied has a checksum that no longer matches
+// Registry, since that means the migration's SQL changed after databases
+// already ran it.
+func Up(db *sql.DB, dialect string) error {
+	if err := ensureSchemaMigrationsTable(db); err != nil {
+		return fmt.Errorf("migrations: ensure schema_migrations: %w", err)
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return fmt.Errorf("migrations: read schema_migrations: %w", err)
+	}
+
+	insertSQL := fmt.Sprintf(
+		"INSERT INTO schema_migrations (version, description, checksum, applied_at) VALUES (%s, %s, %s, %s)",
+		placeholder(dialect, 1), placeholder(dialect, 2), placeholder(dialect, 3), placeholder(dialect, 4),
+	)
+
+	for _, m := range sorted() {
+		want := checksum(m)
+		if got, ok := applied[m.Version]; ok {
+			if got != want {
+				return fmt.Errorf("migrations: version %d checksum mismatch (schema changed after it was applied)", m.Version)
+			}
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("migrations: begin version %d: %w", m.Version, err)
+		}
+		if _, err := tx.Exec(m.Up); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrations: apply version %d (%s): %w", m.Version, m.Description, err)
+		}
+		if _, err := tx.Exec(insertSQL, m.Version, m.Description, want, time.Now().UTC()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrations: record version %d: %w", m.Version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migrations: commit version %d: %w", m.Version, err)
+		}
+	}
+	return nil
+}
+
+// Down rolls back the steps most-recently-applied migrations, in reverse
+// version order, each inside its own transaction.
+func Down(db *sql.DB, dialect string, steps int) error {
+	if steps <= 0 {
+		return nil
+	}
+	if err := ensureSchemaMigrationsTable(db); err != nil {
+		return fmt.Errorf("migrations: ensure schema_migrations: %w", err)
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return fmt.Errorf("migrations: read schema_migrations: %w", err)
+	}
+
+	deleteSQL := fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %s", placeholder(dialect, 1))
+
+	reverse := sorted()
+	for i, j := 0, len(reverse)-1; i < j; i, j = i+1, j-1 {
+		reverse[i], reverse[j] = reverse[j], reverse[i]
+	}
+
+	rolledBack := 0
+	for _, m := range reverse {
+		if rolledBack >= steps {
+			break
+		}
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("migrations: begin rollback of version %d: %w", m.Version, err)
+		}
+		if _, err := tx.Exec(m.Down); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrations: rollback version %d (%s): %w", m.Version, m.Description, err)
+		}
+		if _, err := tx.Exec(deleteSQL, m.Version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrations: unrecord version %d: %w", m.Version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migrations: commit rollback of version %d: %w", m.Version, err)
+		}
+		rolledBack++
+	}
+	return nil
+}
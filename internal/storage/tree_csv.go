@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+var treeCSVHeader = []string{"rowid", "p1", "p2", "p3", "p4", "y_def"}
+
+// ExportTreeCSV dumps every row of tree_<layer> to a CSV file at csvPath,
+// base64-encoding the BLOB columns, so the work LE.Upd already did building
+// that layer can be shipped to another machine instead of redone there.
+func ExportTreeCSV(db *sql.DB, layer int, csvPath string) error {
+	f, err := os.Create(csvPath)
+	if err != nil {
+		return fmt.Errorf("storage: create %s: %w", csvPath, err)
+	}
+	defer f.Close()
+
+	query := fmt.Sprintf("SELECT rowid, p1, p2, P3, p4, y_def FROM %s", treeTableName(layer))
+	rows, err := db.Query(query)
+	if err != nil {
+		return fmt.Errorf("storage: query %s: %w", treeTableName(layer), err)
+	}
+	defer rows.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(treeCSVHeader); err != nil {
+		return fmt.Errorf("storage: write csv header: %w", err)
+	}
+
+	for rows.Next() {
+		var rowid int64
+		var p1, p2, p3, p4 []byte
+		var yDef bool
+		if err := rows.Scan(&rowid, &p1, &p2, &p3, &p4, &yDef); err != nil {
+			return fmt.Errorf("storage: scan row: %w", err)
+		}
+		record := []string{
+			strconv.FormatInt(rowid, 10),
+			base64.StdEncoding.EncodeToString(p1),
+			base64.StdEncoding.EncodeToString(p2),
+			base64.StdEncoding.EncodeToString(p3),
+			base64.StdEncoding.EncodeToString(p4),
+			strconv.FormatBool(yDef),
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("storage: write csv row: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("storage: iterate rows: %w", err)
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// ImportTreeCSV reloads a tree_<layer> dump produced by ExportTreeCSV into
+// db, inside one transaction. The table must already exist (OpenTreeStore/
+// InitializeTreeDB creates it); ImportTreeCSV only repopulates rows.
+func ImportTreeCSV(db *sql.DB, layer int, csvPath string) error {
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return fmt.Errorf("storage: open %s: %w", csvPath, err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	if _, err := r.Read(); err != nil {
+		return fmt.Errorf("storage: read csv header: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("storage: begin import: %w", err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (rowid, p1, p2, P3, p4, y_def) VALUES (?, ?, ?, ?, ?, ?)", treeTableName(layer))
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("storage: prepare import insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("storage: read csv row: %w", err)
+		}
+		if len(record) != len(treeCSVHeader) {
+			tx.Rollback()
+			return fmt.Errorf("storage: csv row has %d columns, want %d", len(record), len(treeCSVHeader))
+		}
+
+		rowid, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("storage: parse rowid %q: %w", record[0], err)
+		}
+		p1, err := base64.StdEncoding.DecodeString(record[1])
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("storage: decode p1: %w", err)
+		}
+		p2, err := base64.StdEncoding.DecodeString(record[2])
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("storage: decode p2: %w", err)
+		}
+		p3, err := base64.StdEncoding.DecodeString(record[3])
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("storage: decode P3: %w", err)
+		}
+		p4, err := base64.StdEncoding.DecodeString(record[4])
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("storage: decode p4: %w", err)
+		}
+		yDef, err := strconv.ParseBool(record[5])
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("storage: parse y_def %q: %w", record[5], err)
+		}
+
+		if _, err := stmt.Exec(rowid, p1, p2, p3, p4, yDef); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("storage: insert row %d: %w", rowid, err)
+		}
+	}
+
+	return tx.Commit()
+}
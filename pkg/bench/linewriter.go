@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenLineSink opens the destination named by spec for LineWriter: a
+// "tcp://host:port" or "udp://host:port" URL dials a socket (matching the
+// -metrics-sink=tcp://host:8094 convention line-protocol tools like
+// telegraf's socket_listener use), and anything else is treated as a file
+// path to append to.
+func OpenLineSink(spec string) (io.WriteCloser, error) {
+	switch {
+	case strings.HasPrefix(spec, "tcp://"):
+		conn, err := net.Dial("tcp", strings.TrimPrefix(spec, "tcp://"))
+		if err != nil {
+			return nil, fmt.Errorf("bench: dial tcp metrics sink %s: %w", spec, err)
+		}
+		return conn, nil
+	case strings.HasPrefix(spec, "udp://"):
+		conn, err := net.Dial("udp", strings.TrimPrefix(spec, "udp://"))
+		if err != nil {
+			return nil, fmt.Errorf("bench: dial udp metrics sink %s: %w", spec, err)
+		}
+		return conn, nil
+	default:
+		f, err := os.OpenFile(spec, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("bench: open metrics sink file %s: %w", spec, err)
+		}
+		return f, nil
+	}
+}
+
+// LineWriter serializes samples as InfluxDB line protocol
+// (measurement,tag=value field=value unix_nanos) to a sink opened by
+// OpenLineSink, so a benchmark run's progress can be watched live in
+// whatever TSDB/Grafana setup already ingests line protocol, instead of
+// only being visible in the JSON/HTML report written after every test
+// completes.
+type LineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewLineWriter wraps w (as returned by OpenLineSink) in a LineWriter.
+func NewLineWriter(w io.Writer) *LineWriter {
+	return &LineWriter{w: w}
+}
+
+// WriteLine writes one line-protocol record. Tags and fields are sorted by
+// key for deterministic output; fields must be non-empty, since line
+// protocol requires at least one field per line.
+func (lw *LineWriter) WriteLine(measurement string, tags map[string]string, fields map[string]float64, ts time.Time) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("bench: line protocol record %q has no fields", measurement)
+	}
+
+	var b strings.Builder
+	b.WriteString(escapeLineProtocol(measurement))
+
+	for _, k := range sortedKeys(tags) {
+		fmt.Fprintf(&b, ",%s=%s", escapeLineProtocol(k), escapeLineProtocol(tags[k]))
+	}
+
+	b.WriteByte(' ')
+	for i, k := range sortedFieldKeys(fields) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%v", escapeLineProtocol(k), fields[k])
+	}
+
+	fmt.Fprintf(&b, " %d\n", ts.UnixNano())
+
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	_, err := io.WriteString(lw.w, b.String())
+	if err != nil {
+		return fmt.Errorf("bench: write line protocol record: %w", err)
+	}
+	return nil
+}
+
+// escapeLineProtocol escapes the characters line protocol treats specially
+// in measurement/tag/field names and tag values: commas, spaces, and equals
+// signs.
+func escapeLineProtocol(s string) string {
+	r := strings.NewReplacer(",", "\\,", " ", "\\ ", "=", "\\=")
+	return r.Replace(s)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFieldKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
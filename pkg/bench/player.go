@@ -0,0 +1,71 @@
+package bench
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Player streams the records a Recorder wrote to path back for post-hoc
+// analysis, one Record at a time, without holding the whole log in memory.
+type Player struct {
+	f *os.File
+	r *bufio.Reader
+}
+
+// OpenPlayer opens the log file a Recorder wrote at path.
+func OpenPlayer(path string) (*Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("bench: open recorder log %s: %w", path, err)
+	}
+	return &Player{f: f, r: bufio.NewReader(f)}, nil
+}
+
+// Close releases the underlying file. It does not drain Play's channel.
+func (p *Player) Close() error {
+	return p.f.Close()
+}
+
+// Play streams every Record in the log to the returned channel in the order
+// Recorder wrote them, closing it once the log is exhausted. A read error
+// (other than EOF) is sent as errCh's single value before both channels
+// close.
+func (p *Player) Play() (<-chan Record, <-chan error) {
+	records := make(chan Record)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(records)
+		defer close(errs)
+
+		for {
+			var length [4]byte
+			if _, err := io.ReadFull(p.r, length[:]); err != nil {
+				if !errors.Is(err, io.EOF) {
+					errs <- fmt.Errorf("bench: read record length: %w", err)
+				}
+				return
+			}
+
+			payload := make([]byte, binary.BigEndian.Uint32(length[:]))
+			if _, err := io.ReadFull(p.r, payload); err != nil {
+				errs <- fmt.Errorf("bench: read record payload: %w", err)
+				return
+			}
+
+			var rec Record
+			if err := json.Unmarshal(payload, &rec); err != nil {
+				errs <- fmt.Errorf("bench: unmarshal record: %w", err)
+				return
+			}
+			records <- rec
+		}
+	}()
+
+	return records, errs
+}
@@ -0,0 +1,276 @@
+// Package bench implements a recorder/player/summarizer pipeline for
+// long-running PSI benchmarks, modeled on perfmonger: a Recorder samples
+// process memory in the background and appends each sample to a binary
+// log, a Player streams that log back for post-hoc analysis, and a
+// Summarizer reduces the samples between caller-inserted markers into
+// per-phase min/avg/p99/peak. This exists because scalability_tests'
+// runScalabilityTest only captured RAM at four discrete phase boundaries,
+// which hides the peak spikes during witness generation that are the
+// actual cause of the OOMs it was built to diagnose.
+package bench
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sample is one point-in-time reading of process memory. HeapAllocMB and
+// HeapInUseMB come from runtime/metrics (Go-managed heap only); VmRSSMB,
+// VmPeakMB and VmSwapMB come from /proc/self/status, since the Go heap
+// alone undercounts whatever the lattigo ring/matrix types and cgo, if any,
+// allocate outside it. CgroupMemoryCurrentMB and CgroupMemoryMaxMB come from
+// the cgroup controller (v2, falling back to v1) and are 0 when the process
+// isn't running under a memory cgroup.
+type Sample struct {
+	HeapAllocMB           float64 `json:"heap_alloc_mb"`
+	HeapInUseMB           float64 `json:"heap_inuse_mb"`
+	VmRSSMB               float64 `json:"vm_rss_mb"`
+	VmPeakMB              float64 `json:"vm_peak_mb"`
+	VmSwapMB              float64 `json:"vm_swap_mb"`
+	CgroupMemoryCurrentMB float64 `json:"cgroup_memory_current_mb,omitempty"`
+	CgroupMemoryMaxMB     float64 `json:"cgroup_memory_max_mb,omitempty"`
+	NumGoroutines         int     `json:"num_goroutines"`
+}
+
+// Record is one entry in a Recorder's log: either a Sample taken on its
+// sampling interval, or a named Marker inserted by the caller to delimit a
+// phase for Summarizer.
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Marker    string    `json:"marker,omitempty"`
+	Sample    *Sample   `json:"sample,omitempty"`
+}
+
+// Recorder samples process memory every interval in a background goroutine
+// and appends each sample as a length-prefixed JSON record to a log file.
+// Callers insert markers with Mark to delimit the phases Summarizer reports
+// on.
+type Recorder struct {
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+
+	mu       sync.Mutex
+	f        *os.File
+	w        *bufio.Writer
+	onRecord func(Record)
+}
+
+// NewRecorder creates the log file at path and starts sampling immediately.
+func NewRecorder(path string, interval time.Duration) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("bench: create recorder log %s: %w", path, err)
+	}
+
+	r := &Recorder{
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		f:        f,
+		w:        bufio.NewWriter(f),
+	}
+	go r.run()
+	return r, nil
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			s := sampleProcess()
+			r.append(Record{Timestamp: time.Now(), Sample: &s})
+		}
+	}
+}
+
+// Mark appends a named marker, e.g. rec.Mark("server_init_start"), that
+// Summarizer uses to delimit the phase whose samples follow it.
+func (r *Recorder) Mark(name string) {
+	r.append(Record{Timestamp: time.Now(), Marker: name})
+}
+
+// OnRecord registers a callback invoked with every Record as it's appended,
+// in addition to the log write - e.g. to stream samples out as InfluxDB
+// line protocol via a LineWriter for live dashboards, instead of only being
+// able to inspect them after Close via a Player. Must be called before the
+// first Mark/sample; it is not safe to change concurrently with sampling.
+func (r *Recorder) OnRecord(fn func(Record)) {
+	r.onRecord = fn
+}
+
+func (r *Recorder) append(rec Record) {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		log.Printf("bench: marshal record: %v", err)
+		return
+	}
+
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(payload)))
+
+	r.mu.Lock()
+	if _, err := r.w.Write(length[:]); err != nil {
+		log.Printf("bench: write record length: %v", err)
+		r.mu.Unlock()
+		return
+	}
+	if _, err := r.w.Write(payload); err != nil {
+		log.Printf("bench: write record payload: %v", err)
+	}
+	r.mu.Unlock()
+
+	if r.onRecord != nil {
+		r.onRecord(rec)
+	}
+}
+
+// Close stops sampling and flushes the log to disk.
+func (r *Recorder) Close() error {
+	close(r.stop)
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.w.Flush(); err != nil {
+		return fmt.Errorf("bench: flush recorder log: %w", err)
+	}
+	return r.f.Close()
+}
+
+// sampledMetricNames are the runtime/metrics samples sampleProcess reads on
+// every tick.
+var sampledMetricNames = []string{
+	"/memory/classes/heap/objects:bytes",
+	"/memory/classes/heap/unused:bytes",
+}
+
+func sampleProcess() Sample {
+	samples := make([]metrics.Sample, len(sampledMetricNames))
+	for i, name := range sampledMetricNames {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+
+	var heapObjects, heapUnused uint64
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		heapObjects = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		heapUnused = samples[1].Value.Uint64()
+	}
+
+	rssMB, peakMB, swapMB := readProcStatusMB()
+	cgroupCurrentMB, cgroupMaxMB := readCgroupMemoryMB()
+
+	return Sample{
+		HeapAllocMB:           float64(heapObjects) / 1024 / 1024,
+		HeapInUseMB:           float64(heapObjects+heapUnused) / 1024 / 1024,
+		VmRSSMB:               rssMB,
+		VmPeakMB:              peakMB,
+		VmSwapMB:              swapMB,
+		CgroupMemoryCurrentMB: cgroupCurrentMB,
+		CgroupMemoryMaxMB:     cgroupMaxMB,
+		NumGoroutines:         runtime.NumGoroutine(),
+	}
+}
+
+// readProcStatusMB reads VmRSS/VmPeak/VmSwap from /proc/self/status. On
+// non-Linux platforms (or any other read failure) it returns zeros rather
+// than an error, since these fields are a best-effort supplement to the Go
+// heap metrics above, not the primary signal.
+func readProcStatusMB() (rssMB, peakMB, swapMB float64) {
+	data, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return 0, 0, 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		switch {
+		case strings.HasPrefix(line, "VmRSS:"):
+			rssMB = parseStatusKB(line)
+		case strings.HasPrefix(line, "VmPeak:"):
+			peakMB = parseStatusKB(line)
+		case strings.HasPrefix(line, "VmSwap:"):
+			swapMB = parseStatusKB(line)
+		}
+	}
+	return rssMB, peakMB, swapMB
+}
+
+// parseStatusKB parses a "VmRSS:    12345 kB" line into megabytes.
+func parseStatusKB(line string) float64 {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return 0
+	}
+	kb, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0
+	}
+	return kb / 1024
+}
+
+// readCgroupMemoryMB reads the process's memory cgroup current usage and
+// limit, preferring cgroup v2's unified hierarchy and falling back to
+// cgroup v1. It returns zeros (not an error) when neither is present, e.g.
+// outside a container, since this is a best-effort supplement like
+// readProcStatusMB.
+func readCgroupMemoryMB() (currentMB, maxMB float64) {
+	if cur, ok := readCgroupValueMB("/sys/fs/cgroup/memory.current"); ok {
+		currentMB = cur
+		if max, ok := readCgroupValueMB("/sys/fs/cgroup/memory.max"); ok {
+			maxMB = max
+		}
+		return currentMB, maxMB
+	}
+
+	if cur, ok := readCgroupValueMB("/sys/fs/cgroup/memory/memory.usage_in_bytes"); ok {
+		currentMB = cur
+		if max, ok := readCgroupValueMB("/sys/fs/cgroup/memory/memory.limit_in_bytes"); ok {
+			maxMB = max
+		}
+		return currentMB, maxMB
+	}
+
+	return 0, 0
+}
+
+// readCgroupValueMB reads a single-line byte-count cgroup file (e.g.
+// memory.current) into megabytes. cgroup v2's "max" for an unlimited
+// memory.max, and cgroup v1's sentinel near-MaxInt64 for an unlimited
+// memory.limit_in_bytes, both report ok=false rather than a huge number.
+func readCgroupValueMB(path string) (mb float64, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	s := strings.TrimSpace(string(data))
+	if s == "max" {
+		return 0, false
+	}
+	bytes, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	// cgroup v1 reports an unlimited limit as a huge number close to
+	// MaxInt64 rounded down to a page boundary, rather than a sentinel.
+	if bytes > 1<<62 {
+		return 0, false
+	}
+	return float64(bytes) / 1024 / 1024, true
+}
@@ -0,0 +1,125 @@
+package bench
+
+import "sort"
+
+// PhaseSummary is the reduced min/avg/p99/peak of one metric over the
+// samples within a phase.
+type PhaseSummary struct {
+	Min  float64 `json:"min"`
+	Avg  float64 `json:"avg"`
+	P99  float64 `json:"p99"`
+	Peak float64 `json:"peak"`
+}
+
+// Phase is every tracked metric's PhaseSummary for the samples recorded
+// between one marker and the next (or the end of the log).
+type Phase struct {
+	Name                  string       `json:"name"`
+	SampleCount           int          `json:"sample_count"`
+	HeapAllocMB           PhaseSummary `json:"heap_alloc_mb"`
+	HeapInUseMB           PhaseSummary `json:"heap_inuse_mb"`
+	VmRSSMB               PhaseSummary `json:"vm_rss_mb"`
+	VmPeakMB              PhaseSummary `json:"vm_peak_mb"`
+	VmSwapMB              PhaseSummary `json:"vm_swap_mb"`
+	CgroupMemoryCurrentMB PhaseSummary `json:"cgroup_memory_current_mb"`
+	CgroupMemoryMaxMB     PhaseSummary `json:"cgroup_memory_max_mb"`
+}
+
+// UnmarkedPhase names the samples recorded before the recording's first
+// Mark call.
+const UnmarkedPhase = "unmarked"
+
+// Summarize reduces a stream of Records (as produced by Player.Play) into
+// one Phase per marker: the phase named by a marker covers every Sample
+// between it and the next marker. Samples recorded before the first marker
+// are grouped under UnmarkedPhase. A marker followed by no samples yields no
+// Phase, and samples under a repeated marker name are merged into that
+// name's single Phase.
+func Summarize(records <-chan Record) []Phase {
+	type collector struct {
+		heapAlloc, heapInUse, vmRSS, vmPeak, vmSwap []float64
+		cgroupCurrent, cgroupMax                    []float64
+	}
+
+	var order []string
+	byName := make(map[string]*collector)
+	current := UnmarkedPhase
+
+	ensure := func(name string) *collector {
+		c, ok := byName[name]
+		if !ok {
+			c = &collector{}
+			byName[name] = c
+			order = append(order, name)
+		}
+		return c
+	}
+
+	for rec := range records {
+		if rec.Marker != "" {
+			current = rec.Marker
+			ensure(current)
+			continue
+		}
+		if rec.Sample == nil {
+			continue
+		}
+		c := ensure(current)
+		c.heapAlloc = append(c.heapAlloc, rec.Sample.HeapAllocMB)
+		c.heapInUse = append(c.heapInUse, rec.Sample.HeapInUseMB)
+		c.vmRSS = append(c.vmRSS, rec.Sample.VmRSSMB)
+		c.vmPeak = append(c.vmPeak, rec.Sample.VmPeakMB)
+		c.vmSwap = append(c.vmSwap, rec.Sample.VmSwapMB)
+		c.cgroupCurrent = append(c.cgroupCurrent, rec.Sample.CgroupMemoryCurrentMB)
+		c.cgroupMax = append(c.cgroupMax, rec.Sample.CgroupMemoryMaxMB)
+	}
+
+	phases := make([]Phase, 0, len(order))
+	for _, name := range order {
+		c := byName[name]
+		if len(c.heapAlloc) == 0 {
+			continue
+		}
+		phases = append(phases, Phase{
+			Name:                  name,
+			SampleCount:           len(c.heapAlloc),
+			HeapAllocMB:           summarizeSeries(c.heapAlloc),
+			HeapInUseMB:           summarizeSeries(c.heapInUse),
+			VmRSSMB:               summarizeSeries(c.vmRSS),
+			VmPeakMB:              summarizeSeries(c.vmPeak),
+			VmSwapMB:              summarizeSeries(c.vmSwap),
+			CgroupMemoryCurrentMB: summarizeSeries(c.cgroupCurrent),
+			CgroupMemoryMaxMB:     summarizeSeries(c.cgroupMax),
+		})
+	}
+	return phases
+}
+
+// summarizeSeries computes min/avg/p99/peak over one metric's samples
+// within a phase.
+func summarizeSeries(values []float64) PhaseSummary {
+	if len(values) == 0 {
+		return PhaseSummary{}
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+
+	idx := int(0.99 * float64(len(sorted)))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+
+	return PhaseSummary{
+		Min:  sorted[0],
+		Avg:  sum / float64(len(values)),
+		P99:  sorted[idx],
+		Peak: sorted[len(sorted)-1],
+	}
+}
@@ -0,0 +1,201 @@
+package psi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// BloomPrefilter is a classical Bloom filter used to reject client queries
+// before they reach the expensive homomorphic decrypt path in
+// DetectIntersectionWithContext. It never produces false negatives: any item
+// actually present in the server's set always passes the filter, so skipping
+// a rejected index cannot hide a real match. False positives just mean the
+// (cheap) filter check didn't save any work for that item.
+//
+// The bit array size m and hash count k are derived from the expected set
+// size n and target false-positive rate p:
+//
+//	m = ceil(-n * ln(p) / (ln 2)^2)
+//	k = round((m/n) * ln 2)
+//
+// The k bit positions for a given key are derived from two independent
+// 64-bit hashes via double hashing: h_i(x) = h1(x) + i*h2(x) mod m.
+type BloomPrefilter struct {
+	bits []uint64 // packed bit array, 64 bits per word
+	m    uint64   // number of bits
+	k    int      // number of hash functions
+	n    int      // number of items inserted
+}
+
+// NewBloomPrefilter creates an empty Bloom filter sized for n expected
+// elements at a target false-positive rate p (e.g. 0.01 for 1%).
+func NewBloomPrefilter(n int, p float64) *BloomPrefilter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := int(math.Round((float64(m) / float64(n)) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &BloomPrefilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// splitmix64 derives two independent 64-bit hashes from a raw client hash,
+// reusing the output entropy of utils.HashDataPoints without pulling in a
+// second hash primitive. See https://prng.di.unimi.it/splitmix64.c.
+func splitmix64(x uint64) uint64 {
+	x += 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	x = x ^ (x >> 31)
+	return x
+}
+
+func (bf *BloomPrefilter) positions(x uint64) (h1, h2 uint64) {
+	h1 = splitmix64(x)
+	h2 = splitmix64(x ^ 0xD6E8FEB86659FD93)
+	if h2 == 0 {
+		h2 = 1 // a zero step would collapse every h_i to h1
+	}
+	return h1, h2
+}
+
+// Add inserts a raw 64-bit client/server hash into the filter.
+func (bf *BloomPrefilter) Add(x uint64) {
+	h1, h2 := bf.positions(x)
+	for i := 0; i < bf.k; i++ {
+		bit := (h1 + uint64(i)*h2) % bf.m
+		bf.bits[bit/64] |= 1 << (bit % 64)
+	}
+	bf.n++
+}
+
+// Test reports whether x might be a member of the filter. A false result is
+// a guarantee of non-membership; a true result may be a false positive.
+func (bf *BloomPrefilter) Test(x uint64) bool {
+	h1, h2 := bf.positions(x)
+	for i := 0; i < bf.k; i++ {
+		bit := (h1 + uint64(i)*h2) % bf.m
+		if bf.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadFactor returns the fraction of bits currently set, a proxy for how
+// close the filter is to its designed-for false-positive rate.
+func (bf *BloomPrefilter) LoadFactor() float64 {
+	set := 0
+	for _, word := range bf.bits {
+		set += popcount(word)
+	}
+	return float64(set) / float64(bf.m)
+}
+
+// FPRate estimates the current false-positive rate from the observed load
+// factor: (set_bits/m)^k.
+func (bf *BloomPrefilter) FPRate() float64 {
+	return math.Pow(bf.LoadFactor(), float64(bf.k))
+}
+
+func popcount(x uint64) int {
+	count := 0
+	for x != 0 {
+		x &= x - 1
+		count++
+	}
+	return count
+}
+
+// BuildBloomPrefilter constructs a filter sized for len(hashes) at the given
+// target false-positive rate and inserts every hash.
+func BuildBloomPrefilter(hashes []uint64, targetFPR float64) *BloomPrefilter {
+	bf := NewBloomPrefilter(len(hashes), targetFPR)
+	for _, h := range hashes {
+		bf.Add(h)
+	}
+	return bf
+}
+
+// bloomFileMagic identifies the on-disk Bloom filter format so
+// LoadBloomPrefilter can refuse to parse an unrelated file.
+const bloomFileMagic = "LEPSIBLM"
+
+// SaveBloomPrefilter persists bf to path, alongside the SQLite witness tree
+// database, so a server process can reload it instead of rebuilding from
+// scratch on restart.
+func SaveBloomPrefilter(bf *BloomPrefilter, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create bloom filter file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(bloomFileMagic); err != nil {
+		return err
+	}
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint64(header[0:8], bf.m)
+	binary.LittleEndian.PutUint64(header[8:16], uint64(bf.k))
+	binary.LittleEndian.PutUint64(header[16:24], uint64(bf.n))
+	if _, err := f.Write(header); err != nil {
+		return err
+	}
+	for _, word := range bf.bits {
+		if err := binary.Write(f, binary.LittleEndian, word); err != nil {
+			return fmt.Errorf("write bloom filter bits: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadBloomPrefilter reads a filter previously written by SaveBloomPrefilter.
+func LoadBloomPrefilter(path string) (*BloomPrefilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(bloomFileMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return nil, fmt.Errorf("read bloom filter magic: %w", err)
+	}
+	if string(magic) != bloomFileMagic {
+		return nil, fmt.Errorf("not a bloom filter file: %s", path)
+	}
+
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return nil, fmt.Errorf("read bloom filter header: %w", err)
+	}
+	bf := &BloomPrefilter{
+		m: binary.LittleEndian.Uint64(header[0:8]),
+		k: int(binary.LittleEndian.Uint64(header[8:16])),
+		n: int(binary.LittleEndian.Uint64(header[16:24])),
+	}
+	bf.bits = make([]uint64, (bf.m+63)/64)
+	for i := range bf.bits {
+		if err := binary.Read(f, binary.LittleEndian, &bf.bits[i]); err != nil {
+			return nil, fmt.Errorf("read bloom filter bits: %w", err)
+		}
+	}
+	return bf, nil
+}
@@ -0,0 +1,481 @@
+package psi
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/matrix"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"golang.org/x/crypto/hkdf"
+)
+
+// cxtxBinaryMagic identifies the framed Cxtx batch wire format
+// MarshalCiphertexts/MarshalCiphertextsStream write, so UnmarshalCiphertexts
+// fails fast on unrelated data instead of misreading it as a header. The
+// trailing "\x01\x00" is the format version, following
+// paramsBinaryMagic/paramsBinaryVersion's split - bump it instead of the
+// "LEPSI" prefix if the chunk layout below changes.
+const cxtxBinaryMagic = "LEPSI\x00\x01\x00"
+
+// cxtxBinaryChunkSize is MarshalCiphertextsStream's default flushEvery when
+// a caller passes <1. MarshalCiphertexts does not use it: it always writes
+// cs as one chunk.
+const cxtxBinaryChunkSize = 256
+
+// maxCxtxChunkRecords bounds the numRecords a single chunk may claim, so a
+// truncated or corrupted stream can't drive readCxtxChunk's allocation up to
+// the ~4 billion a raw wire uint32 could otherwise claim before its MAC is
+// even checked. It's sized generously - MarshalCiphertexts puts an entire
+// server/client set into one chunk - well above any set size this repo's
+// intersection path is exercised against.
+const maxCxtxChunkRecords = 1 << 20
+
+// cxtxEndOfStream is the numRecords value that marks the final chunk of a
+// Cxtx batch stream.
+const cxtxEndOfStream = 0
+
+// CxtxBinaryHeader is the fixed prefix UnmarshalCiphertexts/
+// UnmarshalCiphertextsStream read before any ciphertext record, so a
+// receiver can check Matches against its own LE parameters before spending
+// any time decoding - let alone decrypting - the records that follow.
+type CxtxBinaryHeader struct {
+	D      int
+	Q      uint64
+	Layers int
+	Count  int // -1 when the sender didn't know the total up front (MarshalCiphertextsStream)
+}
+
+// Matches reports whether hdr was encoded against le's ring
+// dimension/modulus/layer count, so a caller can reject a Cxtx stream
+// before decrypting any of it instead of discovering the mismatch through
+// garbage CorrectnessCheck results.
+func (hdr CxtxBinaryHeader) Matches(le *LE.LE) bool {
+	return hdr.D == le.D && hdr.Q == le.Q && hdr.Layers == le.Layers
+}
+
+// MarshalCiphertexts writes cs as a single MAC-protected chunk, preceded by
+// a CxtxBinaryHeader carrying le's ring dimension/modulus/layer count and
+// len(cs), so UnmarshalCiphertexts can reject a stream encoded against
+// different LE parameters - or with fewer/more ciphertexts than declared -
+// before any record reaches DetectIntersectionWithContext. See
+// MarshalCiphertextsStream for a variant that doesn't need cs fully in
+// memory up front and flushes every K ciphertexts.
+//
+// The chunk MAC is HMAC-SHA256 keyed by HKDF(salt), salt being a random
+// value written into the header: this catches bit flips or truncation
+// between sender and receiver, but - since the salt travels in the clear
+// right next to the data it authenticates - it isn't a defense against a
+// forger who can rewrite the whole stream, since they can recompute the
+// same HKDF output from the salt they just read. Defending against that
+// would need a secret the receiver already holds, folded into the HKDF
+// input alongside the salt, which isn't plumbed through this signature.
+func MarshalCiphertexts(w io.Writer, cs []Cxtx, le *LE.LE) error {
+	ch := make(chan Cxtx, len(cs))
+	for _, c := range cs {
+		ch <- c
+	}
+	close(ch)
+
+	flushEvery := len(cs)
+	if flushEvery < 1 {
+		flushEvery = 1
+	}
+	return marshalCiphertexts(w, ch, le, flushEvery, len(cs))
+}
+
+// MarshalCiphertextsStream is MarshalCiphertexts for a producer that
+// doesn't have its whole ciphertext batch in memory up front: it flushes a
+// MAC-protected chunk every flushEvery ciphertexts read from cs, so a
+// receiver running UnmarshalCiphertextsStream can start feeding
+// DetectIntersectionStream from the first chunk before this call - or the
+// client encrypting into cs - has finished. The header's Count field is
+// written as -1, since the total isn't known until cs closes.
+func MarshalCiphertextsStream(w io.Writer, cs <-chan Cxtx, le *LE.LE, flushEvery int) error {
+	return marshalCiphertexts(w, cs, le, flushEvery, -1)
+}
+
+func marshalCiphertexts(w io.Writer, cs <-chan Cxtx, le *LE.LE, flushEvery int, count int) error {
+	if flushEvery < 1 {
+		flushEvery = cxtxBinaryChunkSize
+	}
+
+	var salt [32]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return fmt.Errorf("psi: generate cxtx salt: %w", err)
+	}
+	if err := writeCxtxBinaryHeader(w, le, salt, count); err != nil {
+		return err
+	}
+	key := deriveCxtxMACKey(salt)
+
+	var chunkIdx uint32
+	buf := make([]Cxtx, 0, flushEvery)
+	for c := range cs {
+		buf = append(buf, c)
+		if len(buf) >= flushEvery {
+			if err := writeCxtxChunk(w, key, chunkIdx, buf, le.D); err != nil {
+				return err
+			}
+			chunkIdx++
+			buf = buf[:0]
+		}
+	}
+	if len(buf) > 0 {
+		if err := writeCxtxChunk(w, key, chunkIdx, buf, le.D); err != nil {
+			return err
+		}
+		chunkIdx++
+	}
+	// Terminating empty chunk, so the receiver knows no more chunks follow
+	// without needing Count to be set.
+	return writeCxtxChunk(w, key, chunkIdx, nil, le.D)
+}
+
+// UnmarshalCiphertexts reads a batch written by MarshalCiphertexts (or
+// MarshalCiphertextsStream) from r in full, verifying every chunk's MAC -
+// and, once the terminating empty chunk arrives, the header's declared
+// Count against how many records were actually delivered - before
+// returning. A MAC failure or count mismatch returns an error and no
+// ciphertexts; the caller never sees a partially-trusted batch.
+func UnmarshalCiphertexts(r io.Reader) ([]Cxtx, error) {
+	out := make(chan Cxtx)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := UnmarshalCiphertextsStream(r, out)
+		errCh <- err
+	}()
+
+	var all []Cxtx
+	for c := range out {
+		all = append(all, c)
+	}
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// UnmarshalCiphertextsStream reads a MarshalCiphertexts/
+// MarshalCiphertextsStream batch from r, verifying and delivering one
+// chunk at a time: out only receives a chunk's records once that whole
+// chunk's MAC has checked out, so a caller feeding DetectIntersectionStream
+// from out never decrypts a tampered or truncated record, even though it
+// can start decrypting the first chunk before the sender has finished
+// writing later ones. UnmarshalCiphertextsStream always closes out before
+// returning, and returns the header it read so the caller can check
+// hdr.Matches against its own LE parameters - independently of the
+// per-chunk MAC, which only proves the bytes weren't altered in transit,
+// not that they were encoded against the parameters the caller expects.
+func UnmarshalCiphertextsStream(r io.Reader, out chan<- Cxtx) (CxtxBinaryHeader, error) {
+	defer close(out)
+
+	hdr, ringQ, key, err := readCxtxBinaryHeader(r)
+	if err != nil {
+		return hdr, err
+	}
+
+	delivered := 0
+	var chunkIdx uint32
+	for {
+		records, end, err := readCxtxChunk(r, ringQ, hdr.D, key, chunkIdx)
+		if err != nil {
+			return hdr, err
+		}
+		if end {
+			break
+		}
+		for _, rec := range records {
+			out <- rec
+		}
+		delivered += len(records)
+		chunkIdx++
+	}
+
+	if hdr.Count >= 0 && delivered != hdr.Count {
+		return hdr, fmt.Errorf("psi: cxtx stream declared %d ciphertexts but delivered %d", hdr.Count, delivered)
+	}
+	return hdr, nil
+}
+
+func deriveCxtxMACKey(salt [32]byte) []byte {
+	kdf := hkdf.New(sha256.New, salt[:], nil, []byte("le-psi-cxtx-mac"))
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		panic(fmt.Sprintf("psi: derive cxtx mac key: %v", err))
+	}
+	return key
+}
+
+func writeCxtxBinaryHeader(w io.Writer, le *LE.LE, salt [32]byte, count int) error {
+	if _, err := w.Write([]byte(cxtxBinaryMagic)); err != nil {
+		return fmt.Errorf("psi: write cxtx magic: %w", err)
+	}
+	fields := []interface{}{int32(le.D), le.Q, int32(le.Layers), int32(count)}
+	for _, f := range fields {
+		if err := binary.Write(w, binary.LittleEndian, f); err != nil {
+			return fmt.Errorf("psi: write cxtx header field: %w", err)
+		}
+	}
+	if _, err := w.Write(salt[:]); err != nil {
+		return fmt.Errorf("psi: write cxtx salt: %w", err)
+	}
+	return nil
+}
+
+// readCxtxBinaryHeader reads the header and returns, alongside it, the
+// ring.Ring hdr.D/hdr.Q describe and the MAC key HKDF-derives from the
+// header's salt, so the caller doesn't have to re-derive either from hdr.
+func readCxtxBinaryHeader(r io.Reader) (CxtxBinaryHeader, *ring.Ring, []byte, error) {
+	var hdr CxtxBinaryHeader
+
+	magic := make([]byte, len(cxtxBinaryMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return hdr, nil, nil, fmt.Errorf("psi: read cxtx magic: %w", err)
+	}
+	if string(magic) != cxtxBinaryMagic {
+		return hdr, nil, nil, fmt.Errorf("psi: not a binary Cxtx stream (bad magic %q)", magic)
+	}
+
+	var d, layers, count int32
+	var q uint64
+	fields := []interface{}{&d, &q, &layers, &count}
+	for _, f := range fields {
+		if err := binary.Read(r, binary.LittleEndian, f); err != nil {
+			return hdr, nil, nil, fmt.Errorf("psi: read cxtx header field: %w", err)
+		}
+	}
+
+	var salt [32]byte
+	if _, err := io.ReadFull(r, salt[:]); err != nil {
+		return hdr, nil, nil, fmt.Errorf("psi: read cxtx salt: %w", err)
+	}
+
+	hdr.D = int(d)
+	hdr.Q = q
+	hdr.Layers = int(layers)
+	hdr.Count = int(count)
+
+	ringQ, err := ring.NewRing(hdr.D, []uint64{hdr.Q})
+	if err != nil {
+		return hdr, nil, nil, fmt.Errorf("psi: create ring: %w", err)
+	}
+
+	return hdr, ringQ, deriveCxtxMACKey(salt), nil
+}
+
+// writeCxtxChunk writes one MAC-protected chunk: chunkIdx and len(records)
+// (both fed into the MAC to stop a truncated/reordered/replayed chunk from
+// verifying), then each record, then the HMAC-SHA256 over all of that.
+// records == nil writes the zero-record end-of-stream marker.
+func writeCxtxChunk(w io.Writer, key []byte, chunkIdx uint32, records []Cxtx, d int) error {
+	mac := hmac.New(sha256.New, key)
+	mw := io.MultiWriter(w, mac)
+
+	var idxBuf [4]byte
+	binary.LittleEndian.PutUint32(idxBuf[:], chunkIdx)
+	if _, err := mw.Write(idxBuf[:]); err != nil {
+		return fmt.Errorf("psi: write cxtx chunk index: %w", err)
+	}
+
+	var cntBuf [4]byte
+	binary.LittleEndian.PutUint32(cntBuf[:], uint32(len(records)))
+	if _, err := mw.Write(cntBuf[:]); err != nil {
+		return fmt.Errorf("psi: write cxtx chunk count: %w", err)
+	}
+
+	for i, c := range records {
+		if err := writeCxtxRecord(mw, c, d); err != nil {
+			return fmt.Errorf("psi: write cxtx record %d in chunk %d: %w", i, chunkIdx, err)
+		}
+	}
+
+	if _, err := w.Write(mac.Sum(nil)); err != nil {
+		return fmt.Errorf("psi: write cxtx chunk mac: %w", err)
+	}
+	return nil
+}
+
+// readCxtxChunk reads and verifies one chunk written by writeCxtxChunk. It
+// rejects the chunk - without returning any of its records - if its index
+// doesn't match expectedChunkIdx (catches reordering/replay) or its MAC
+// doesn't check out (catches corruption/tampering), in both cases before
+// any record is decoded into a usable Cxtx. end is true once the
+// end-of-stream marker has been read and verified.
+func readCxtxChunk(r io.Reader, ringQ *ring.Ring, d int, key []byte, expectedChunkIdx uint32) (records []Cxtx, end bool, err error) {
+	mac := hmac.New(sha256.New, key)
+	tr := io.TeeReader(r, mac)
+
+	var idxBuf [4]byte
+	if _, err := io.ReadFull(tr, idxBuf[:]); err != nil {
+		return nil, false, fmt.Errorf("psi: read cxtx chunk index: %w", err)
+	}
+	chunkIdx := binary.LittleEndian.Uint32(idxBuf[:])
+	if chunkIdx != expectedChunkIdx {
+		return nil, false, fmt.Errorf("psi: cxtx chunk out of order (got index %d, want %d)", chunkIdx, expectedChunkIdx)
+	}
+
+	var cntBuf [4]byte
+	if _, err := io.ReadFull(tr, cntBuf[:]); err != nil {
+		return nil, false, fmt.Errorf("psi: read cxtx chunk count: %w", err)
+	}
+	numRecords := binary.LittleEndian.Uint32(cntBuf[:])
+
+	if numRecords == cxtxEndOfStream {
+		if err := verifyCxtxChunkMAC(r, mac); err != nil {
+			return nil, false, err
+		}
+		return nil, true, nil
+	}
+	if numRecords > maxCxtxChunkRecords {
+		return nil, false, fmt.Errorf("psi: cxtx chunk claims %d records, exceeds limit %d", numRecords, maxCxtxChunkRecords)
+	}
+
+	out := make([]Cxtx, numRecords)
+	for i := range out {
+		c, err := readCxtxRecord(tr, ringQ, d)
+		if err != nil {
+			return nil, false, fmt.Errorf("psi: read cxtx record %d in chunk %d: %w", i, chunkIdx, err)
+		}
+		out[i] = c
+	}
+
+	if err := verifyCxtxChunkMAC(r, mac); err != nil {
+		return nil, false, err
+	}
+	return out, false, nil
+}
+
+func verifyCxtxChunkMAC(r io.Reader, mac hash.Hash) error {
+	var got [32]byte
+	if _, err := io.ReadFull(r, got[:]); err != nil {
+		return fmt.Errorf("psi: read cxtx chunk mac: %w", err)
+	}
+	if subtle.ConstantTimeCompare(got[:], mac.Sum(nil)) != 1 {
+		return errors.New("psi: cxtx chunk failed MAC verification (corrupt or tampered stream)")
+	}
+	return nil
+}
+
+func writeCxtxRecord(w io.Writer, c Cxtx, d int) error {
+	if err := writeCxtxVectors(w, c.C0, d); err != nil {
+		return fmt.Errorf("C0: %w", err)
+	}
+	if err := writeCxtxVectors(w, c.C1, d); err != nil {
+		return fmt.Errorf("C1: %w", err)
+	}
+	if err := writeCxtxVector(w, c.C, d); err != nil {
+		return fmt.Errorf("C: %w", err)
+	}
+	if err := writeCxtxPoly(w, c.D, d); err != nil {
+		return fmt.Errorf("D: %w", err)
+	}
+	return nil
+}
+
+func readCxtxRecord(r io.Reader, ringQ *ring.Ring, d int) (Cxtx, error) {
+	var c Cxtx
+	var err error
+	if c.C0, err = readCxtxVectors(r, ringQ, d); err != nil {
+		return c, fmt.Errorf("C0: %w", err)
+	}
+	if c.C1, err = readCxtxVectors(r, ringQ, d); err != nil {
+		return c, fmt.Errorf("C1: %w", err)
+	}
+	if c.C, err = readCxtxVector(r, ringQ, d); err != nil {
+		return c, fmt.Errorf("C: %w", err)
+	}
+	if c.D, err = readCxtxPoly(r, ringQ, d); err != nil {
+		return c, fmt.Errorf("D: %w", err)
+	}
+	return c, nil
+}
+
+func writeCxtxVectors(w io.Writer, vecs []*matrix.Vector, d int) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(vecs))); err != nil {
+		return err
+	}
+	for _, v := range vecs {
+		if err := writeCxtxVector(w, v, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readCxtxVectors(r io.Reader, ringQ *ring.Ring, d int) ([]*matrix.Vector, error) {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+	vecs := make([]*matrix.Vector, n)
+	for i := range vecs {
+		v, err := readCxtxVector(r, ringQ, d)
+		if err != nil {
+			return nil, err
+		}
+		vecs[i] = v
+	}
+	return vecs, nil
+}
+
+func writeCxtxVector(w io.Writer, v *matrix.Vector, d int) error {
+	n := 0
+	if v != nil {
+		n = len(v.Elements)
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(n)); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := writeCxtxPoly(w, v.Elements[i], d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readCxtxVector(r io.Reader, ringQ *ring.Ring, d int) (*matrix.Vector, error) {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+	elems := make([]*ring.Poly, n)
+	for i := range elems {
+		p, err := readCxtxPoly(r, ringQ, d)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = p
+	}
+	return &matrix.Vector{Elements: elems}, nil
+}
+
+func writeCxtxPoly(w io.Writer, p *ring.Poly, d int) error {
+	coeffs := polyCoeffsOrZero(p, d)
+	buf := make([]byte, 8*d)
+	for i, c := range coeffs {
+		binary.LittleEndian.PutUint64(buf[i*8:], c)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func readCxtxPoly(r io.Reader, ringQ *ring.Ring, d int) (*ring.Poly, error) {
+	buf := make([]byte, 8*d)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	poly := ringQ.NewPoly()
+	for i := 0; i < d; i++ {
+		poly.Coeffs[0][i] = binary.LittleEndian.Uint64(buf[i*8:])
+	}
+	return poly, nil
+}
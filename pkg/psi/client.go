@@ -1,6 +1,9 @@
 package psi
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"io"
 	"runtime"
 	"sync"
 
@@ -8,6 +11,7 @@ import (
 	"github.com/SanthoshCheemala/LE-PSI/pkg/matrix"
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/utils"
+	"golang.org/x/crypto/hkdf"
 )
 
 // ClientEncrypt encrypts the client's private dataset using the server's public parameters.
@@ -23,39 +27,212 @@ import (
 //   - []Cxtx: Slice of encrypted ciphertexts, one per element in private_set_Y
 //
 // Example:
-//   clientData := []uint64{150, 200, 250}
-//   pp, msg, le := psi.GetPublicParameters(serverCtx)
-//   ciphertexts := psi.ClientEncrypt(clientData, pp, msg, le)
-//   // Send ciphertexts to server for intersection detection
+//
+//	clientData := []uint64{150, 200, 250}
+//	pp, msg, le := psi.GetPublicParameters(serverCtx)
+//	ciphertexts := psi.ClientEncrypt(clientData, pp, msg, le)
+//	// Send ciphertexts to server for intersection detection
 func ClientEncrypt(private_set_Y []uint64, pp *matrix.Vector, msg *ring.Poly, le *LE.LE) []Cxtx {
-	return Client(private_set_Y, pp, msg, le)
+	return ClientWithOptions(private_set_Y, pp, msg, le, ClientOptions{})
 }
 
 func Client(private_set_Y []uint64, pp *matrix.Vector, msg *ring.Poly, le *LE.LE) []Cxtx {
-	Y_size := len(private_set_Y)
+	return ClientWithOptions(private_set_Y, pp, msg, le, ClientOptions{})
+}
 
-	treeIndices := make([]uint64, Y_size)
-	for i := 0; i < Y_size; i++ {
-		treeIndices[i] = ReduceToTreeIndex(private_set_Y[i], le.Layers)
+// ClientDeterministic is Client with every worker's PRNG derived from seed,
+// making its Cxtx output bit-for-bit reproducible across runs regardless of
+// GOMAXPROCS/NumCPU. pkg/psi/testvectors drives this to check ClientEncrypt's
+// output against a pinned conformance vector.
+func ClientDeterministic(private_set_Y []uint64, pp *matrix.Vector, msg *ring.Poly, le *LE.LE, seed []byte) []Cxtx {
+	return ClientWithOptions(private_set_Y, pp, msg, le, ClientOptions{Deterministic: true, Seed: seed})
+}
+
+// ClientWithPolicy is ClientEncrypt sized by policy's
+// CalculateOptimalWorkersWithPolicy instead of runtime.NumCPU(), the
+// client-side counterpart to ServerInitialize's WithWorkerPolicy - useful
+// for a client encrypting on the same memory-constrained host the server
+// runs on, rather than assuming every core is free to spend on encryption.
+func ClientWithPolicy(private_set_Y []uint64, pp *matrix.Vector, msg *ring.Poly, le *LE.LE, policy WorkerPolicy) []Cxtx {
+	return ClientWithOptions(private_set_Y, pp, msg, le, ClientOptions{Policy: &policy})
+}
+
+// ClientOptions configures ClientWithOptions's PRNG selection.
+type ClientOptions struct {
+	// PRNGFactory, if set, produces the PRNG worker workerID's goroutine
+	// draws its r/e0/e1/e samples from. Takes precedence over Deterministic.
+	PRNGFactory func(workerID int) (utils.PRNG, error)
+
+	// Deterministic requests reproducible encryption: each worker's PRNG is
+	// keyed off Seed via HKDF, with the worker's ID mixed into the HKDF info
+	// parameter, so re-running with the same Seed always produces the same
+	// ciphertexts no matter how many workers ran in parallel.
+	Deterministic bool
+	Seed          []byte
+
+	// MaxWorkers caps the number of worker goroutines; 0 means Policy (if
+	// set) sizes the pool instead, or runtime.NumCPU() if Policy is also
+	// nil.
+	MaxWorkers int
+
+	// Policy sizes the worker pool via CalculateOptimalWorkersWithPolicy
+	// when MaxWorkers is 0, in place of runtime.NumCPU(). Nil preserves
+	// ClientEncrypt's historical behavior. The caller is responsible for
+	// calling Policy.Calibrate against le first if it wants a measured
+	// MemPerRecordBytes rather than WorkerPolicy's default estimate.
+	Policy *WorkerPolicy
+}
+
+// ClientWithOptions is ClientEncrypt generalized with a pluggable PRNG
+// source, so callers needing reproducible ciphertexts (test vectors,
+// benchmark comparisons, bug reports) aren't stuck with ClientEncrypt's
+// real-entropy utils.NewPRNG.
+func ClientWithOptions(private_set_Y []uint64, pp *matrix.Vector, msg *ring.Poly, le *LE.LE, opts ClientOptions) []Cxtx {
+	maxWorkers := resolveWorkerCount(len(private_set_Y), opts)
+	factory := resolvePRNGFactory(opts)
+	return clientWithPRNG(private_set_Y, pp, msg, le, factory, maxWorkers)
+}
+
+// resolveWorkerCount picks ClientWithOptions' worker pool size: opts.MaxWorkers
+// if set, else opts.Policy sized against n if set, else runtime.NumCPU().
+func resolveWorkerCount(n int, opts ClientOptions) int {
+	if opts.MaxWorkers > 0 {
+		return opts.MaxWorkers
+	}
+	if opts.Policy != nil {
+		return CalculateOptimalWorkersWithPolicy(n, *opts.Policy)
+	}
+	return runtime.NumCPU()
+}
+
+// resolvePRNGFactory picks ClientWithOptions' prngFactory: opts.PRNGFactory
+// if set, else a Seed-derived deterministic factory if opts.Deterministic,
+// else a real-entropy utils.NewPRNG per worker.
+func resolvePRNGFactory(opts ClientOptions) prngFactory {
+	if opts.PRNGFactory != nil {
+		return opts.PRNGFactory
+	}
+	if opts.Deterministic {
+		return func(workerID int) (utils.PRNG, error) {
+			return utils.NewKeyedPRNG(deriveWorkerSeed(opts.Seed, workerID))
+		}
+	}
+	return func(int) (utils.PRNG, error) {
+		return utils.NewPRNG()
+	}
+}
+
+// deriveWorkerSeed expands seed into a worker-specific key via HKDF, keyed
+// on workerID through the info parameter, so every worker's PRNG is
+// independent yet reproducible from the same top-level seed.
+func deriveWorkerSeed(seed []byte, workerID int) []byte {
+	info := []byte(fmt.Sprintf("le-psi-client-worker-%d", workerID))
+	kdf := hkdf.New(sha256.New, seed, nil, info)
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		panic(fmt.Sprintf("psi: derive worker seed: %v", err))
+	}
+	return key
+}
+
+// prngFactory produces the PRNG worker workerID's goroutine draws its
+// r/e0/e1/e samples from.
+type prngFactory func(workerID int) (utils.PRNG, error)
+
+// ClientBucketIDs computes the witness-tree bucket id clientWithPRNG derives
+// internally for each element of private_set_Y, so a caller can pass them
+// to DetectIntersectionWithContextBucketed alongside the Cxtx slice
+// ClientEncrypt returns. layers must be the same le.Layers the Cxtx slice
+// was encrypted against.
+func ClientBucketIDs(private_set_Y []uint64, layers int) []uint64 {
+	ids := make([]uint64, len(private_set_Y))
+	for i, y := range private_set_Y {
+		ids[i] = ReduceToTreeIndex(y, layers)
 	}
+	return ids
+}
+
+// ClientEncryptCuckoo is ClientEncrypt for a server built with
+// WithCuckooHashing: each element of private_set_Y is encrypted against k+1
+// lanes - seeds.Candidates' k candidate leaves, plus one trailing
+// ReduceToTreeIndex(y, le.Layers) lane. That trailing lane only matches a
+// server element placed by ReduceToTreeIndex directly. A server element
+// BuildCuckooLayout stashed after exhausting maxKicks evictions sits on the
+// lowest leaf left free after cuckoo placement (see placeStash), which none
+// of y's lanes targets except by coincidence; since a ciphertext only
+// decrypts correctly against the server element on its own leaf, neither
+// DetectIntersectionWithContextBucketed nor DetectIntersectionWithContext's
+// full scan matches stashed elements otherwise.
+// Returned ciphertexts are grouped k+1 per item: result[i*(k+1) : i*(k+1)+k+1]
+// are private_set_Y[i]'s lanes, in the order seeds.Candidates returns them
+// followed by the trailing lane. Pass the parallel bucket ids this produces
+// (ClientCuckooBucketIDs) to DetectIntersectionWithContextBucketed, which
+// already implements "decrypt only against server indices sharing this
+// leaf" and needs no cuckoo-specific server-side code.
+func ClientEncryptCuckoo(private_set_Y []uint64, pp *matrix.Vector, msg *ring.Poly, le *LE.LE, seeds CuckooSeeds, k int, opts ClientOptions) []Cxtx {
+	treeIndices := cuckooLaneIndices(private_set_Y, seeds, k, le.Layers)
+	maxWorkers := resolveWorkerCount(len(treeIndices), opts)
+	factory := resolvePRNGFactory(opts)
+	return clientEncryptAtIndices(treeIndices, pp, msg, le, factory, maxWorkers)
+}
+
+// ClientCuckooBucketIDs computes the bucket ids ClientEncryptCuckoo's
+// ciphertexts landed on, for DetectIntersectionWithContextBucketed - the
+// cuckoo-hashed counterpart to ClientBucketIDs. k and layers must match the
+// values ClientEncryptCuckoo was called with.
+func ClientCuckooBucketIDs(private_set_Y []uint64, seeds CuckooSeeds, k, layers int) []uint64 {
+	return cuckooLaneIndices(private_set_Y, seeds, k, layers)
+}
+
+// cuckooLaneIndices flattens each element of private_set_Y into its k cuckoo
+// candidates plus one trailing ReduceToTreeIndex lane, backing both
+// ClientEncryptCuckoo and ClientCuckooBucketIDs so the two always agree on
+// lane order.
+func cuckooLaneIndices(private_set_Y []uint64, seeds CuckooSeeds, k, layers int) []uint64 {
+	lanesPerItem := k + 1
+	indices := make([]uint64, 0, len(private_set_Y)*lanesPerItem)
+	for _, y := range private_set_Y {
+		indices = append(indices, seeds.Candidates(y, k, layers)...)
+		indices = append(indices, ReduceToTreeIndex(y, layers))
+	}
+	return indices
+}
+
+func clientWithPRNG(private_set_Y []uint64, pp *matrix.Vector, msg *ring.Poly, le *LE.LE, newPRNG prngFactory, maxWorkers int) []Cxtx {
+	treeIndices := make([]uint64, len(private_set_Y))
+	for i, y := range private_set_Y {
+		treeIndices[i] = ReduceToTreeIndex(y, le.Layers)
+	}
+	return clientEncryptAtIndices(treeIndices, pp, msg, le, newPRNG, maxWorkers)
+}
+
+// clientEncryptAtIndices is clientWithPRNG generalized to take precomputed
+// witness-tree leaves directly, instead of deriving one ReduceToTreeIndex
+// leaf per private_set_Y element - the shared core ClientEncryptCuckoo needs
+// to encrypt several lanes per item rather than exactly one.
+func clientEncryptAtIndices(treeIndices []uint64, pp *matrix.Vector, msg *ring.Poly, le *LE.LE, newPRNG prngFactory, maxWorkers int) []Cxtx {
+	Y_size := len(treeIndices)
 
 	C := make([]Cxtx, Y_size)
 	cipherChan := make(chan int, Y_size)
 	var cipherWg sync.WaitGroup
 
-	numWorkers := runtime.NumCPU()
+	numWorkers := maxWorkers
 	if numWorkers > Y_size {
 		numWorkers = Y_size
 	}
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
 
 	for w := 0; w < numWorkers; w++ {
 		cipherWg.Add(1)
-		go func() {
+		go func(workerID int) {
 			defer cipherWg.Done()
-			
-			workerPRNG, _ := utils.NewPRNG()
+
+			workerPRNG, _ := newPRNG(workerID)
 			workerGaussianSampler := ring.NewGaussianSampler(workerPRNG, le.R, le.Sigma, le.Bound)
-			
+
 			for i := range cipherChan {
 				r := make([]*matrix.Vector, le.Layers+1)
 				for j := 0; j < le.Layers+1; j++ {
@@ -77,7 +254,7 @@ func Client(private_set_Y []uint64, pp *matrix.Vector, msg *ring.Poly, le *LE.LE
 				c0, c1, cvec, dpoly := LE.Enc(le, pp, treeIndices[i], msg, r, e0, e1, e)
 				C[i] = Cxtx{C0: c0, C1: c1, C: cvec, D: dpoly}
 			}
-		}()
+		}(w)
 	}
 
 	for i := 0; i < Y_size; i++ {
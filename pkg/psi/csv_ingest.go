@@ -0,0 +1,106 @@
+package psi
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+// defaultCSVIngestBufferSize bounds how many CSV rows ServerInitializeFromCSV
+// batches in memory before hashing and discarding them, independent of how
+// many rows the file contains.
+const defaultCSVIngestBufferSize = 10000
+
+// CSVIngestOption configures ServerInitializeFromCSV.
+type CSVIngestOption func(*csvIngestConfig)
+
+type csvIngestConfig struct {
+	bufferSize int
+}
+
+// WithCSVBufferSize overrides the row-batch size ServerInitializeFromCSV
+// buffers before hashing, trading peak memory for fewer, larger batches.
+func WithCSVBufferSize(rows int) CSVIngestOption {
+	return func(cfg *csvIngestConfig) {
+		if rows > 0 {
+			cfg.bufferSize = rows
+		}
+	}
+}
+
+// ServerInitializeFromCSV builds a ServerInitContext from a CSV/TSV dump
+// instead of a pre-built []uint64, for operators whose private set starts
+// life as a transaction export (see storage.RetriveData/Transaction).
+//
+// It streams the file in batches of at most bufferSize rows (configurable
+// via WithCSVBufferSize), hashing keyColumn's value in each row with hashFn
+// and discarding the row immediately after - only the resulting hash slice
+// is held for the whole file, not the CSV text or parsed rows, so the memory
+// spent on rows is bounded by the batch size regardless of set size, while
+// the hash slice itself grows by one uint64 per row. That hash slice
+// is then handed to ServerInitialize unchanged, since its parallel
+// keygen/witness pipelines need random access across the whole private set
+// and cannot themselves run incrementally.
+func ServerInitializeFromCSV(path string, keyColumn string, treepath string, hashFn func(string) uint64, opts ...CSVIngestOption) (*ServerInitContext, error) {
+	cfg := csvIngestConfig{bufferSize: defaultCSVIngestBufferSize}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("psi: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("psi: read csv header: %w", err)
+	}
+
+	keyIdx := -1
+	for i, name := range header {
+		if name == keyColumn {
+			keyIdx = i
+			break
+		}
+	}
+	if keyIdx < 0 {
+		return nil, fmt.Errorf("psi: column %q not found in %s header %v", keyColumn, path, header)
+	}
+
+	var hashes []uint64
+	batch := make([]string, 0, cfg.bufferSize)
+	flush := func() {
+		for _, key := range batch {
+			hashes = append(hashes, hashFn(key))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("psi: read csv row: %w", err)
+		}
+		if keyIdx >= len(record) {
+			continue
+		}
+		batch = append(batch, record[keyIdx])
+		if len(batch) >= cfg.bufferSize {
+			flush()
+		}
+	}
+	flush()
+
+	if len(hashes) == 0 {
+		return nil, fmt.Errorf("psi: %s has no usable %q rows", path, keyColumn)
+	}
+
+	return ServerInitialize(hashes, treepath)
+}
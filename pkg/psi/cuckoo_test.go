@@ -0,0 +1,43 @@
+package psi
+
+import "testing"
+
+// TestBuildCuckooLayoutNoCollisions forces stash overflow by giving the
+// layout exactly as many leaves as elements (load factor 1 with only k=2
+// candidates and a tiny maxKicks), then asserts every element - whether placed by
+// cuckoo candidate eviction or by placeStash's fallback scan - ends up with
+// a distinct Leaf. A single repeated Leaf value here means two server
+// elements would silently overwrite each other's witness data in
+// ServerInitialize's LE.Upd loop.
+func TestBuildCuckooLayoutNoCollisions(t *testing.T) {
+	const layers = 6 // 2^6 = 64 leaves, one per element
+	serverData := buildServerSet(64)
+
+	layout, err := BuildCuckooLayout(serverData, layers, 2, 1)
+	if err != nil {
+		t.Fatalf("BuildCuckooLayout: %v", err)
+	}
+	if len(layout.Stash) == 0 {
+		t.Fatalf("expected this undersized layout (layers=%d, %d elements) to force stash overflow, but nothing was stashed", layers, len(serverData))
+	}
+
+	seen := make(map[uint64]int, len(serverData))
+	for i, leaf := range layout.Leaf {
+		if other, taken := seen[leaf]; taken {
+			t.Fatalf("elements %d and %d both assigned leaf %d; ServerInitialize would silently overwrite one's witness data with the other's", other, i, leaf)
+		}
+		seen[leaf] = i
+	}
+}
+
+// TestBuildCuckooLayoutExhaustedTreeErrors checks that a dataset that
+// can't possibly fit in 2^layers leaves - even with placeStash's fallback
+// scan - fails loudly instead of silently reusing a leaf.
+func TestBuildCuckooLayoutExhaustedTreeErrors(t *testing.T) {
+	const layers = 2 // 2^2 = 4 leaves
+	serverData := buildServerSet(32)
+
+	if _, err := BuildCuckooLayout(serverData, layers, 2, 1); err == nil {
+		t.Fatalf("expected BuildCuckooLayout to error when %d elements can't fit in %d leaves", len(serverData), 1<<layers)
+	}
+}
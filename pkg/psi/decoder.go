@@ -0,0 +1,460 @@
+package psi
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/SanthoshCheemala/LE-PSI/internal/storage"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/matrix"
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// Confidence summarizes how close a Decoder's match/no-match verdict was,
+// so a caller can filter borderline intersections instead of trusting a
+// boolean blindly.
+type Confidence struct {
+	// MatchRate is the fraction of coefficients (ThresholdDecoder) or
+	// repetition groups (MajorityVoteDecoder/ErrorCorrectingDecoder) whose
+	// recovered bit agreed with original.
+	MatchRate float64
+	// Distance is how far the observed verdict sat from its decision
+	// boundary: MatchRate minus the configured threshold for
+	// ThresholdDecoder/MajorityVoteDecoder, or the configured
+	// MaxMismatchedGroups minus the observed mismatched group count for
+	// ErrorCorrectingDecoder. Larger is more confident; values near zero are
+	// borderline; negative means the verdict was "no match".
+	Distance float64
+}
+
+// Decoder turns a decrypted polynomial into a match verdict against
+// original, replacing CorrectnessCheck's hardcoded 95% threshold / [Q/4,
+// 3Q/4] window with a pluggable strategy so a caller can tune for noisier
+// parameter sets, or tell a genuine near-miss from a wholly wrong
+// decryption via Confidence.
+type Decoder interface {
+	// Scheme identifies this Decoder, mirroring utils.Hasher.Scheme.
+	Scheme() string
+	Decode(decrypted, original *ring.Poly, le *LE.LE) (matched bool, confidence Confidence)
+}
+
+// binarizedMatchRate rounds each of decrypted's coefficients to 0 or 1 by
+// CorrectnessCheck's historical [Q/4, 3Q/4] window, and returns the fraction
+// that agree with the corresponding coefficient of original. Every Decoder
+// in this file is built on top of this same rounding rule; they differ only
+// in how they turn per-coefficient agreement into a verdict.
+func binarizedMatchRate(decrypted, original *ring.Poly, le *LE.LE) float64 {
+	q14 := le.Q / 4
+	q34 := (le.Q / 4) * 3
+
+	n := le.R.N
+	matchCount := 0
+	for i := 0; i < n; i++ {
+		bit := uint64(0)
+		if decrypted.Coeffs[0][i] >= q14 && decrypted.Coeffs[0][i] <= q34 {
+			bit = 1
+		}
+		if bit == original.Coeffs[0][i] {
+			matchCount++
+		}
+	}
+	return float64(matchCount) / float64(n)
+}
+
+// ThresholdDecoder is CorrectnessCheck's original strategy: a match if at
+// least MatchRate of decrypted's coefficients agree with original.
+type ThresholdDecoder struct {
+	// MatchRate is the minimum agreement fraction; 0 means 0.95, matching
+	// CorrectnessCheck's historical hardcoded value.
+	MatchRate float64
+}
+
+func (d ThresholdDecoder) Scheme() string { return "threshold" }
+
+func (d ThresholdDecoder) threshold() float64 {
+	if d.MatchRate > 0 {
+		return d.MatchRate
+	}
+	return 0.95
+}
+
+func (d ThresholdDecoder) Decode(decrypted, original *ring.Poly, le *LE.LE) (bool, Confidence) {
+	rate := binarizedMatchRate(decrypted, original, le)
+	threshold := d.threshold()
+	return rate >= threshold, Confidence{MatchRate: rate, Distance: rate - threshold}
+}
+
+// MajorityVoteDecoder treats decrypted's coefficients as GroupSize-way
+// repetition-coded groups: within each group, the recovered bit is whichever
+// of 0/1 a majority of the group's rounded coefficients voted for, and that
+// recovered bit - not every individual coefficient - is what's compared
+// against original. This tolerates a noisy coefficient or two inside a
+// group without needing MatchRate tuned down, as long as original really
+// was encoded with GroupSize-fold redundancy.
+type MajorityVoteDecoder struct {
+	// GroupSize is how many consecutive coefficients form one repetition
+	// group; 0 means 1 (no redundancy - degenerates to a per-coefficient
+	// majority of one, i.e. ThresholdDecoder's comparison).
+	GroupSize int
+	// MatchRate is the minimum fraction of groups whose majority-vote bit
+	// must agree with original's group bit; 0 means 0.95.
+	MatchRate float64
+}
+
+func (d MajorityVoteDecoder) Scheme() string { return "majority-vote" }
+
+func (d MajorityVoteDecoder) groupSize() int {
+	if d.GroupSize > 0 {
+		return d.GroupSize
+	}
+	return 1
+}
+
+func (d MajorityVoteDecoder) threshold() float64 {
+	if d.MatchRate > 0 {
+		return d.MatchRate
+	}
+	return 0.95
+}
+
+func (d MajorityVoteDecoder) Decode(decrypted, original *ring.Poly, le *LE.LE) (bool, Confidence) {
+	groups, mismatched := groupMismatches(decrypted, original, le, d.groupSize())
+	rate := 1 - float64(mismatched)/float64(groups)
+	threshold := d.threshold()
+	return rate >= threshold, Confidence{MatchRate: rate, Distance: rate - threshold}
+}
+
+// groupMismatches rounds decrypted's coefficients to bits via
+// binarizedMatchRate's [Q/4, 3Q/4] window, partitions them into groups of
+// groupSize consecutive coefficients, takes each group's majority-vote bit
+// as its recovered bit, and counts how many groups' recovered bit disagrees
+// with original's bit at that group's first coefficient. Shared by
+// MajorityVoteDecoder and ErrorCorrectingDecoder, which differ only in how
+// they turn (groups, mismatched) into a verdict.
+func groupMismatches(decrypted, original *ring.Poly, le *LE.LE, groupSize int) (groups, mismatched int) {
+	q14 := le.Q / 4
+	q34 := (le.Q / 4) * 3
+	n := le.R.N
+
+	for start := 0; start < n; start += groupSize {
+		end := start + groupSize
+		if end > n {
+			end = n
+		}
+
+		ones := 0
+		for i := start; i < end; i++ {
+			if decrypted.Coeffs[0][i] >= q14 && decrypted.Coeffs[0][i] <= q34 {
+				ones++
+			}
+		}
+		recoveredBit := uint64(0)
+		if ones*2 >= (end - start) {
+			recoveredBit = 1
+		}
+
+		groups++
+		if recoveredBit != original.Coeffs[0][start] {
+			mismatched++
+		}
+	}
+	return groups, mismatched
+}
+
+// ErrorCorrectingDecoder treats decrypted's rounded coefficients as a
+// repetition codeword - GroupSize copies of each message bit - and tolerates
+// up to MaxMismatchedGroups groups whose majority-vote recovered bit
+// disagrees with original's (see MajorityVoteDecoder), rather than requiring
+// every group to agree.
+//
+// The request this implements asked for "a repetition or BCH codeword";
+// this repo has no BCH implementation to depend on (the same gap
+// CuckooSeeds' doc comment documents for "k SipHash instances"), so only
+// the repetition-code family is implemented here. A real BCH decoder
+// recognizes a richer codeword space than plain repetition, but the
+// repetition-nearest-codeword distance computed here is still a valid lower
+// bound on how far decrypted sits from any codeword of that length.
+type ErrorCorrectingDecoder struct {
+	// GroupSize is the repetition factor; 0 means 1.
+	GroupSize int
+	// MaxMismatchedGroups is the most per-group recovered-bit disagreements
+	// with original still counted as a match; 0 means every group's
+	// recovered bit must agree.
+	MaxMismatchedGroups int
+}
+
+func (d ErrorCorrectingDecoder) Scheme() string { return "error-correcting" }
+
+func (d ErrorCorrectingDecoder) groupSize() int {
+	if d.GroupSize > 0 {
+		return d.GroupSize
+	}
+	return 1
+}
+
+func (d ErrorCorrectingDecoder) Decode(decrypted, original *ring.Poly, le *LE.LE) (bool, Confidence) {
+	groups, mismatched := groupMismatches(decrypted, original, le, d.groupSize())
+
+	matched := mismatched <= d.MaxMismatchedGroups
+	return matched, Confidence{
+		MatchRate: 1 - float64(mismatched)/float64(groups),
+		Distance:  float64(d.MaxMismatchedGroups - mismatched),
+	}
+}
+
+// resolveDecoder returns ctx.Decoder if set, else ThresholdDecoder{} -
+// CorrectnessCheck's historical behavior.
+func resolveDecoder(ctx *ServerInitContext) Decoder {
+	if ctx.Decoder != nil {
+		return ctx.Decoder
+	}
+	return ThresholdDecoder{}
+}
+
+// Match pairs an intersection hit with the Confidence ctx.Decoder assigned
+// it, so a caller can filter out borderline matches instead of trusting
+// DetectIntersectionWithContext's plain boolean membership.
+type Match struct {
+	Value      uint64
+	Confidence Confidence
+}
+
+// confidencePair is one (client ciphertext, server index) decrypt-and-decode
+// job, as generated by confidenceCandidates.
+type confidencePair struct {
+	j, k int
+}
+
+// confidenceUsesBucketMap reports whether bucketIDs is usable to restrict
+// clientCiphertexts against ctx.BucketMap: both confidenceTotalWork and
+// sendConfidencePairs fall back to an unrestricted scan otherwise, so they
+// must agree on this check.
+func confidenceUsesBucketMap(ctx *ServerInitContext, clientCiphertexts []Cxtx, bucketIDs []uint64) bool {
+	return ctx.BucketMap != nil && len(bucketIDs) == len(clientCiphertexts)
+}
+
+// confidenceTotalWork is the number of (ciphertext, server index) pairs
+// sendConfidencePairs will send, computed up front so
+// detectIntersectionConfidence can size its worker channel exactly, the way
+// DetectIntersectionWithContextBucketed (pkg/psi/server.go) sizes its own
+// workItems channel from totalWork before queuing anything.
+func confidenceTotalWork(ctx *ServerInitContext, clientCiphertexts []Cxtx, bucketIDs []uint64) int {
+	if !confidenceUsesBucketMap(ctx, clientCiphertexts, bucketIDs) {
+		return len(clientCiphertexts) * len(ctx.OriginalHashes)
+	}
+	total := 0
+	for _, id := range bucketIDs {
+		total += len(ctx.BucketMap[id])
+	}
+	return total
+}
+
+// sendConfidencePairs is DetectIntersectionWithContextBucketed's candidate
+// restriction (see its doc comment), generalized to send the (ciphertext,
+// server index) pairs detectIntersectionConfidence decodes onto workItems
+// directly, instead of the server-index lists DetectIntersectionWithContextBucketed
+// decrypts directly. When ctx.BucketMap is nil or bucketIDs doesn't have one
+// entry per clientCiphertexts[j], this falls back to every (j, k) pair -
+// the same unrestricted scan DetectIntersectionWithContextConfidence ran
+// before it had a bucketIDs argument to restrict against.
+func sendConfidencePairs(ctx *ServerInitContext, clientCiphertexts []Cxtx, bucketIDs []uint64, workItems chan<- confidencePair) {
+	if !confidenceUsesBucketMap(ctx, clientCiphertexts, bucketIDs) {
+		X_size := len(ctx.OriginalHashes)
+		for j := range clientCiphertexts {
+			for k := 0; k < X_size; k++ {
+				workItems <- confidencePair{j: j, k: k}
+			}
+		}
+		return
+	}
+
+	for j, id := range bucketIDs {
+		for _, k := range ctx.BucketMap[id] {
+			workItems <- confidencePair{j: j, k: k}
+		}
+	}
+}
+
+// DetectIntersectionWithContextConfidence behaves like
+// DetectIntersectionWithContext, but decodes with ctx.Decoder (see
+// WithDecoder; ThresholdDecoder{} - CorrectnessCheck's historical behavior -
+// if unset) and returns each match's Confidence alongside its value, instead
+// of a plain []uint64. DetectIntersectionWithContext and the streaming
+// DetectIntersectionStream/IntersectionSession paths are left decoding with
+// CorrectnessCheck directly, so existing callers' pass/fail semantics don't
+// change out from under them; this is the opt-in entry point for a caller
+// that wants per-match confidence.
+//
+// This always scans every (ciphertext, server index) pair. A caller with
+// ctx.BucketMap (built automatically whenever the server was built with
+// bucketing or WithCuckooHashing) should use
+// DetectIntersectionWithContextConfidenceBucketed instead to restrict that
+// scan the same way DetectIntersectionWithContextBucketed does.
+func DetectIntersectionWithContextConfidence(ctx *ServerInitContext, clientCiphertexts []Cxtx) ([]Match, error) {
+	return detectIntersectionConfidence(ctx, clientCiphertexts, nil)
+}
+
+// DetectIntersectionWithContextConfidenceBucketed is
+// DetectIntersectionWithContextConfidence restricted to
+// ctx.BucketMap[bucketIDs[j]] per clientCiphertexts[j], the confidence-scored
+// counterpart to DetectIntersectionWithContextBucketed. Falls back to
+// DetectIntersectionWithContextConfidence's unrestricted scan if
+// ctx.BucketMap is nil or bucketIDs doesn't have one entry per ciphertext.
+func DetectIntersectionWithContextConfidenceBucketed(ctx *ServerInitContext, clientCiphertexts []Cxtx, bucketIDs []uint64) ([]Match, error) {
+	return detectIntersectionConfidence(ctx, clientCiphertexts, bucketIDs)
+}
+
+// detectIntersectionConfidence is the shared decrypt-and-decode worker pool
+// DetectIntersectionWithContextConfidence and
+// DetectIntersectionWithContextConfidenceBucketed both run, differing only
+// in which (ciphertext, server index) pairs sendConfidencePairs hands them -
+// so bucket/cuckoo placement composes with confidence scoring instead of
+// each entry point reimplementing its own full scan. bucketIDs is nil for
+// the unrestricted entry point.
+func detectIntersectionConfidence(ctx *ServerInitContext, clientCiphertexts []Cxtx, bucketIDs []uint64) ([]Match, error) {
+	decoder := resolveDecoder(ctx)
+
+	monitor := NewPerformanceMonitor()
+	intersectionStart := time.Now()
+
+	X_size := len(ctx.OriginalHashes)
+	numWorkers := CalculateOptimalWorkersWithPolicy(X_size, ctx.WorkerPolicy)
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	totalWork := confidenceTotalWork(ctx, clientCiphertexts, bucketIDs)
+	workItems := make(chan confidencePair, totalWork)
+
+	matches := make(map[int]Confidence)
+	var resultMutex sync.Mutex
+	var detectionWg sync.WaitGroup
+
+	for w := 0; w < numWorkers; w++ {
+		detectionWg.Add(1)
+		go func() {
+			defer detectionWg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("CRITICAL: Worker panic: %v", r)
+				}
+			}()
+
+			metrics := DefaultMetrics()
+			for item := range workItems {
+				j, k := item.j, item.k
+				itemStart := time.Now()
+				msg2 := LE.Dec(ctx.LEParams, ctx.PrivateKeys[k], ctx.WitnessVectors1[k], ctx.WitnessVectors2[k],
+					clientCiphertexts[j].C0, clientCiphertexts[j].C1, clientCiphertexts[j].C, clientCiphertexts[j].D)
+
+				matched, confidence := decoder.Decode(msg2, ctx.Message, ctx.LEParams)
+				if matched {
+					resultMutex.Lock()
+					if existing, ok := matches[k]; !ok {
+						matches[k] = confidence
+						metrics.Matches.Inc()
+					} else if confidence.Distance > existing.Distance {
+						matches[k] = confidence
+					}
+					resultMutex.Unlock()
+				}
+				metrics.DetectionLatency.Observe(time.Since(itemStart).Seconds())
+			}
+		}()
+	}
+
+	sendConfidencePairs(ctx, clientCiphertexts, bucketIDs, workItems)
+	close(workItems)
+	detectionWg.Wait()
+
+	results := make([]Match, 0, len(matches))
+	for k, confidence := range matches {
+		results = append(results, Match{Value: ctx.OriginalHashes[k], Confidence: confidence})
+	}
+
+	monitor.TrackIntersectionDetection(intersectionStart)
+	monitor.TotalOperations = totalWork
+	monitor.Noise = ctx.NoiseTracker
+	monitor.NoiseQ = ctx.LEParams.Q
+	monitor.PrintReport()
+	monitor.Observe(DefaultMetrics())
+
+	return results, nil
+}
+
+// CalibrationReport summarizes the empirical binarizedMatchRate
+// distribution CalibrateDecoder measured across n encrypt/decrypt round
+// trips, so a caller can pick an appropriate Decoder and threshold for le's
+// parameters instead of trusting CorrectnessCheck's historical 95% blindly.
+type CalibrationReport struct {
+	Rounds int
+	Min    float64
+	Max    float64
+	Mean   float64
+	// Rates holds every round's raw match rate, in round order, for a
+	// caller that wants the full distribution rather than just its summary
+	// statistics.
+	Rates []float64
+}
+
+// CalibrateDecoder runs n encrypt/decrypt round trips against a fresh,
+// single-element witness tree built from le - a new random key pair and
+// random message polynomial each round, the two sources of decryption noise
+// CorrectnessCheck's threshold has to tolerate - and reports the empirical
+// distribution of binarizedMatchRate, the quantity every Decoder in this
+// file ultimately thresholds or votes over. treepath is a storage DSN (see
+// storage.OpenBackend); pass ":memory:" for a throwaway calibration run that
+// doesn't touch disk.
+func CalibrateDecoder(le *LE.LE, n int, treepath string) (CalibrationReport, error) {
+	backend, err := storage.OpenBackend(treepath)
+	if err != nil {
+		return CalibrationReport{}, fmt.Errorf("psi: calibration: open backend: %w", err)
+	}
+	defer backend.Close()
+
+	if err := backend.OpenTreeStore(le.Layers); err != nil {
+		return CalibrationReport{}, fmt.Errorf("psi: calibration: open tree store: %w", err)
+	}
+	db := backend.Raw()
+
+	const leaf = 0
+	report := CalibrationReport{Rounds: n, Rates: make([]float64, n)}
+	sum := 0.0
+
+	for round := 0; round < n; round++ {
+		pubKey, privKey := le.KeyGen()
+		LE.Upd(db, leaf, le.Layers, pubKey, le)
+
+		tree, err := LE.LoadTreeFromDB(db, le.Layers, le)
+		if err != nil {
+			return CalibrationReport{}, fmt.Errorf("psi: calibration: load tree (round %d): %w", round, err)
+		}
+		vec1, vec2 := LE.WitGenMemory(tree, le, leaf)
+
+		msg := matrix.NewRandomPolyBinary(le.R)
+		pp := LE.ReadFromDB(db, 0, 0, le).NTT(le.R)
+
+		ciphertexts := ClientWithOptions([]uint64{leaf}, pp, msg, le, ClientOptions{MaxWorkers: 1})
+		c := ciphertexts[0]
+
+		decrypted := LE.Dec(le, privKey, vec1, vec2, c.C0, c.C1, c.C, c.D)
+		rate := binarizedMatchRate(decrypted, msg, le)
+
+		report.Rates[round] = rate
+		sum += rate
+		if round == 0 || rate < report.Min {
+			report.Min = rate
+		}
+		if round == 0 || rate > report.Max {
+			report.Max = rate
+		}
+	}
+	report.Mean = sum / float64(n)
+
+	fmt.Printf("psi: calibration: %d rounds, match rate min=%.4f mean=%.4f max=%.4f\n",
+		report.Rounds, report.Min, report.Mean, report.Max)
+
+	return report, nil
+}
@@ -0,0 +1,56 @@
+package grpcapi
+
+import (
+	"github.com/SanthoshCheemala/LE-PSI/pkg/matrix"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/psi"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/psi/grpcapi/lepsipb"
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// polysToProto converts the [][]uint64 coefficient rows
+// psi.SerializeParameters produces into wire Poly messages.
+func polysToProto(rows [][]uint64) []*lepsipb.Poly {
+	out := make([]*lepsipb.Poly, len(rows))
+	for i, row := range rows {
+		out[i] = &lepsipb.Poly{Coeffs: row}
+	}
+	return out
+}
+
+// coeffsToPoly builds a ring.Poly from a flat coefficient slice, the same
+// way psi.DeserializeParameters does for PublicParams.
+func coeffsToPoly(coeffs []uint64, r *ring.Ring) *ring.Poly {
+	poly := r.NewPoly()
+	copy(poly.Coeffs[0], coeffs)
+	return poly
+}
+
+// protoToVector reconstructs a matrix.Vector from its wire representation.
+func protoToVector(v *lepsipb.Vector, r *ring.Ring) *matrix.Vector {
+	elems := make([]*ring.Poly, len(v.Elements))
+	for i, p := range v.Elements {
+		elems[i] = coeffsToPoly(p.Coeffs, r)
+	}
+	return &matrix.Vector{Elements: elems}
+}
+
+// protoToVectors reconstructs a []*matrix.Vector (one psi.Cxtx.C0/C1 entry
+// per witness-tree layer) from its wire representation.
+func protoToVectors(vecs []*lepsipb.Vector, r *ring.Ring) []*matrix.Vector {
+	out := make([]*matrix.Vector, len(vecs))
+	for i, v := range vecs {
+		out[i] = protoToVector(v, r)
+	}
+	return out
+}
+
+// protoToCxtx reconstructs a psi.Cxtx from its wire representation against
+// r, the server's ring.Ring (ctx.LEParams.R).
+func protoToCxtx(msg *lepsipb.Ciphertext, r *ring.Ring) psi.Cxtx {
+	return psi.Cxtx{
+		C0: protoToVectors(msg.C0, r),
+		C1: protoToVectors(msg.C1, r),
+		C:  protoToVector(msg.C, r),
+		D:  coeffsToPoly(msg.D.Coeffs, r),
+	}
+}
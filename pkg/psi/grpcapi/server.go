@@ -0,0 +1,123 @@
+// Package grpcapi implements the LEPSI gRPC service declared in lepsi.proto
+// on top of psi.ServerInitContext. It depends on the generated
+// lepsipb.LEPSIServer interface and message types, which aren't checked
+// into this repository: run
+//
+//	protoc --go_out=. --go_opt=module=github.com/SanthoshCheemala/LE-PSI \
+//	       --go-grpc_out=. --go-grpc_opt=module=github.com/SanthoshCheemala/LE-PSI \
+//	       pkg/psi/grpcapi/lepsi.proto
+//
+// (with protoc-gen-go and protoc-gen-go-grpc on PATH) to produce
+// pkg/psi/grpcapi/lepsipb/lepsi.pb.go and lepsi_grpc.pb.go before this
+// package will build, so generated code always matches whatever
+// protoc/plugin versions a given checkout has, rather than drifting from a
+// committed snapshot.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/psi"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/psi/grpcapi/lepsipb"
+)
+
+// Server implements lepsipb.LEPSIServer against a resident
+// psi.ServerInitContext, the gRPC counterpart to the HTTP handlers in
+// simulation/server/main.go.
+type Server struct {
+	lepsipb.UnimplementedLEPSIServer
+
+	ctx *psi.ServerInitContext
+}
+
+// NewServer wraps ctx (the output of psi.ServerInitialize) for registration
+// with a *grpc.Server via lepsipb.RegisterLEPSIServer.
+func NewServer(ctx *psi.ServerInitContext) *Server {
+	return &Server{ctx: ctx}
+}
+
+// GetParams implements lepsipb.LEPSIServer.
+func (s *Server) GetParams(ctx context.Context, _ *lepsipb.Empty) (*lepsipb.Params, error) {
+	pp, msg, le := psi.GetPublicParameters(s.ctx)
+	serialized := psi.SerializeParameters(pp, msg, le)
+	return &lepsipb.Params{
+		Pp:         polysToProto(serialized.PP),
+		Msg:        &lepsipb.Poly{Coeffs: serialized.Msg},
+		Q:          serialized.Q,
+		D:          int32(serialized.D),
+		N:          int32(serialized.N),
+		Layers:     int32(serialized.Layers),
+		M:          int32(serialized.M),
+		M2:         int32(serialized.M2),
+		HashScheme: serialized.HashScheme,
+	}, nil
+}
+
+// Status implements lepsipb.LEPSIServer.
+func (s *Server) Status(ctx context.Context, _ *lepsipb.Empty) (*lepsipb.StatusReply, error) {
+	return &lepsipb.StatusReply{
+		Status:      "running",
+		DataSize:    int32(len(s.ctx.OriginalHashes)),
+		DataVersion: s.ctx.DataVersion(),
+	}, nil
+}
+
+// Intersect implements lepsipb.LEPSIServer. It pumps incoming Ciphertext
+// messages into psi.DetectIntersectionStream and streams each Match back as
+// soon as the worker pool confirms it, so the client never has to buffer
+// its whole ciphertext set and the server never has to finish the whole
+// scan before sending the first result.
+func (s *Server) Intersect(stream lepsipb.LEPSI_IntersectServer) error {
+	in := make(chan psi.Cxtx)
+	out := make(chan uint64)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- psi.DetectIntersectionStream(s.ctx, in, out)
+		close(out)
+	}()
+
+	// sendLoop keeps ranging over out - even after stream.Send starts
+	// failing - until psi.DetectIntersectionStream closes it. Returning
+	// early on the first Send error would leave out undrained: its worker
+	// pool would then block forever on out <- ... for any further match,
+	// DetectIntersectionStream would never return to close(out), and the
+	// <-errCh below would hang for the lifetime of the process.
+	sendDone := make(chan error, 1)
+	go func() {
+		var sendErr error
+		for hash := range out {
+			if sendErr != nil {
+				continue
+			}
+			if err := stream.Send(&lepsipb.Match{Hash: hash}); err != nil {
+				sendErr = fmt.Errorf("grpcapi: send match: %w", err)
+			}
+		}
+		sendDone <- sendErr
+	}()
+
+	var recvErr error
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				recvErr = fmt.Errorf("grpcapi: recv ciphertext: %w", err)
+			}
+			close(in)
+			break
+		}
+		in <- protoToCxtx(msg, s.ctx.LEParams.R)
+	}
+
+	if err := <-errCh; err != nil {
+		return fmt.Errorf("grpcapi: intersect: %w", err)
+	}
+	if sendErr := <-sendDone; sendErr != nil {
+		return sendErr
+	}
+	return recvErr
+}
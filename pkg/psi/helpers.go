@@ -41,8 +41,6 @@ package psi
 
 import (
 	"fmt"
-	"log"
-	"math"
 	"os"
 
 	// "sort"
@@ -82,7 +80,8 @@ type Cxtx struct {
 //   - uint64: Tree index (masked hash value) in range [0, 2^layers - 1]
 //
 // Example:
-//   treeIdx := psi.ReduceToTreeIndex(12345678, 10)  // Returns index in [0, 1023]
+//
+//	treeIdx := psi.ReduceToTreeIndex(12345678, 10)  // Returns index in [0, 1023]
 func ReduceToTreeIndex(rawHash uint64, layers int) uint64 {
 	var mask uint64
 	bits := uint(layers)
@@ -105,98 +104,35 @@ func ReduceToTreeIndex(rawHash uint64, layers int) uint64 {
 // Returns:
 //   - bool: true if match rate >= 95%, false otherwise
 //
+// This is ThresholdDecoder{}'s strategy (see decoder.go) - a caller that
+// wants a different coefficient-matching strategy, or per-match confidence
+// instead of a plain bool, should use a Decoder directly (see
+// ServerInitContext.Decoder / WithDecoder / DetectIntersectionWithContextConfidence).
+//
 // Note: Enables verbose logging with PSI_VERBOSE=false environment variable
 func CorrectnessCheck(decrypted, original *ring.Poly, le *LE.LE) bool {
-	q14 := le.Q / 4
-	q34 := (le.Q / 4) * 3
-	binaryDecrypted := le.R.NewPoly()
-	
-	for i := 0; i < le.R.N; i++ {
-		if decrypted.Coeffs[0][i] < q14 || decrypted.Coeffs[0][i] > q34 {
-			binaryDecrypted.Coeffs[0][i] = 0
-		} else {
-			binaryDecrypted.Coeffs[0][i] = 1
-		}
-	}
-	
-	matchCount := 0
-	for i := 0; i < le.R.N; i++ {
-		if binaryDecrypted.Coeffs[0][i] == original.Coeffs[0][i] {
-			matchCount++
-		}
-	}
-	
+	matched, confidence := (ThresholdDecoder{}).Decode(decrypted, original, le)
+
 	if VerboseMode {
-		matchPercentage := float64(matchCount) / float64(le.R.N)
-		fmt.Printf("Match rate: %.2f%% (%d/%d coefficients)\n", matchPercentage*100, matchCount, le.R.N)
+		fmt.Printf("Match rate: %.2f%% (%d/%d coefficients)\n",
+			confidence.MatchRate*100, int(confidence.MatchRate*float64(le.R.N)), le.R.N)
 	}
-	
-	return float64(matchCount)/float64(le.R.N) >= 0.95
+
+	return matched
 }
 
 // CalculateOptimalWorkers determines the optimal number of worker goroutines
-// based on dataset size, available RAM, and hardware constraints.
-//
-// Parameters:
-//   - datasetSize: Number of elements to process
-//
-// Returns:
-//   - int: Optimal number of worker goroutines (between 8 and 48)
-//
-// The function considers:
-//   - Available RAM (117 GB out of 251 GB total)
-//   - Memory per record (~35 MB)
-//   - Hardware limit (48 physical cores on dual-socket Xeon Gold 5418Y)
-//   - Cache optimization for datasets > 100 elements
+// for datasetSize using AutoDetectPolicy() - the host's real CPU count and
+// free RAM - in place of the fixed dual-socket-Xeon constants this function
+// used to hardcode. Callers that already hold a *LE.LE (and so can
+// Calibrate the policy's MemPerRecordBytes against a real measurement, or
+// want to reuse one AutoDetectPolicy() call across several dataset sizes)
+// should call CalculateOptimalWorkersWithPolicy directly instead; see
+// WorkerPolicy's doc comment in worker_policy.go.
 //
 // Example:
-//   workers := psi.CalculateOptimalWorkers(5000)  // Returns ~32 workers
+//
+//	workers := psi.CalculateOptimalWorkers(5000)
 func CalculateOptimalWorkers(datasetSize int) int {
-	// System constraints for dual-socket Intel Xeon Gold 5418Y
-	const (
-		availableRAM_GB  = 117.0 // Available RAM (251 GB total - 134 GB used)
-		memPerRecord_GB  = 0.035 // 35 MB per record (12 MB witness + 13 MB thread + 10 MB overhead)
-		safetyMargin     = 1.15  // 15% safety margin (reduced from 20% - more aggressive)
-		hardwareLimit    = 48    // Physical cores (24 per socket Ã— 2 sockets)
-		practicalMinimum = 8     // Increased from 4 - better for multi-socket systems
-	)
-
-	estimatedMemory := float64(datasetSize) * memPerRecord_GB * safetyMargin
-	memoryLimit := hardwareLimit // Default to hardware limit
-	if estimatedMemory > availableRAM_GB*0.6 {
-		memoryLimit = int((availableRAM_GB * 0.85) / estimatedMemory * float64(hardwareLimit))
-	}
-
-	cacheLimit := hardwareLimit
-	if datasetSize > 100 {
-		// Scale up by 1.5Ã— for better CPU utilization
-		cacheLimit = int(1.5 * math.Sqrt(float64(datasetSize)))
-		if cacheLimit > hardwareLimit {
-			cacheLimit = hardwareLimit
-		}
-		if cacheLimit < 16 {
-			cacheLimit = 16 // Increased from 8 - better for dual-socket NUMA
-		}
-	}
-
-	// Take the minimum of all constraints
-	optimal := memoryLimit
-	if cacheLimit < optimal {
-		optimal = cacheLimit
-	}
-	if hardwareLimit < optimal {
-		optimal = hardwareLimit
-	}
-
-	// Ensure practical minimum for performance
-	if optimal < practicalMinimum {
-		optimal = practicalMinimum
-	}
-
-	// Log the decision for monitoring and debugging
-	estimatedRAM_GB := float64(datasetSize) * memPerRecord_GB
-	log.Printf("ðŸš€ Adaptive Threading (TUNED): %d records â†’ %d workers (est. RAM: %.1f GB, memory limit: %d, cache limit: %d)",
-		datasetSize, optimal, estimatedRAM_GB, memoryLimit, cacheLimit)
-
-	return optimal
-}
\ No newline at end of file
+	return CalculateOptimalWorkersWithPolicy(datasetSize, AutoDetectPolicy())
+}
@@ -0,0 +1,167 @@
+package psi
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/matrix"
+)
+
+// DataVersion returns the number of AddEntries/RemoveEntries calls that have
+// completed against ctx, so a caller holding a cached PublicParams/witness
+// set (e.g. fetched once from /api/status or /api/params) can poll this and
+// refetch when it no longer matches.
+func (ctx *ServerInitContext) DataVersion() uint64 {
+	return atomic.LoadUint64(&ctx.Version)
+}
+
+// AddEntries incrementally inserts hashes into ctx's server set without
+// ServerInitialize's full teardown/rebuild: KeyGen only runs for the new
+// items, LE.Upd only touches their leaves, and witnesses are only
+// regenerated for the indices sharing a witness-tree bucket with one of
+// them (via ctx.BucketMap) rather than every existing entry. PublicParams
+// and ctx.Bloom are refreshed to cover the new items, and Version is bumped
+// so a caller polling /api/status can tell its cached copies are stale.
+//
+// pkg/LE exposes no primitive to patch a Merkle tree's internal nodes in
+// place, so the tree is still reloaded in full from the backing store with
+// LE.LoadTreeFromDB after the new leaves are written - cheaper than
+// ServerInitialize's full KeyGen+Upd+witness-regen pass over every existing
+// entry, but the reload step itself isn't O(len(hashes)*Layers) in the
+// pkg/LE snapshot this repo vendors.
+//
+// AddEntries refuses to run against a server built WithCuckooHashing: it
+// places new entries by plain ReduceToTreeIndex, with no awareness of
+// ctx.Cuckoo's candidate/eviction scheme, so a new entry's raw leaf can
+// collide with a leaf BuildCuckooLayoutWithSeeds already assigned to an
+// existing entry. LE.Upd would then silently overwrite that existing
+// entry's public key - permanently breaking its decryption even though
+// ctx.OriginalHashes/ctx.PrivateKeys still list it as present - and
+// ctx.Cuckoo itself would go stale with no error raised. Rebuild the whole
+// context with ServerInitialize instead.
+func (ctx *ServerInitContext) AddEntries(hashes []uint64) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	if ctx.Backend == nil {
+		return errors.New("psi: AddEntries: context has no storage backend")
+	}
+	if ctx.Cuckoo != nil {
+		return errors.New("psi: AddEntries: server was built WithCuckooHashing; incremental insertion isn't cuckoo-aware and could silently collide with an existing entry's leaf - rebuild with ServerInitialize instead")
+	}
+
+	db := ctx.Backend.Raw()
+	leParams := ctx.LEParams
+
+	newTreeIndices := make([]uint64, len(hashes))
+	newPublicKeys := make([]*matrix.Vector, len(hashes))
+	newPrivateKeys := make([]*matrix.Vector, len(hashes))
+	for i, h := range hashes {
+		newTreeIndices[i] = ReduceToTreeIndex(h, leParams.Layers)
+		newPublicKeys[i], newPrivateKeys[i] = leParams.KeyGen()
+	}
+
+	for i := range hashes {
+		LE.Upd(db, newTreeIndices[i], leParams.Layers, newPublicKeys[i], leParams)
+	}
+
+	ctx.PublicParams = LE.ReadFromDB(db, 0, 0, leParams).NTT(leParams.R)
+
+	memoryTree, err := LE.LoadTreeFromDB(db, leParams.Layers, leParams)
+	if err != nil {
+		return fmt.Errorf("psi: AddEntries: reload tree: %w", err)
+	}
+
+	startIdx := len(ctx.OriginalHashes)
+	ctx.OriginalHashes = append(ctx.OriginalHashes, hashes...)
+	ctx.TreeIndices = append(ctx.TreeIndices, newTreeIndices...)
+	ctx.PrivateKeys = append(ctx.PrivateKeys, newPrivateKeys...)
+	ctx.WitnessVectors1 = append(ctx.WitnessVectors1, make([][]*matrix.Vector, len(hashes))...)
+	ctx.WitnessVectors2 = append(ctx.WitnessVectors2, make([][]*matrix.Vector, len(hashes))...)
+
+	if ctx.BucketMap == nil {
+		ctx.BucketMap = make(map[uint64][]int, len(newTreeIndices))
+	}
+	touched := make(map[int]bool)
+	for i, idx := range newTreeIndices {
+		ctx.BucketMap[idx] = append(ctx.BucketMap[idx], startIdx+i)
+		for _, k := range ctx.BucketMap[idx] {
+			touched[k] = true
+		}
+	}
+
+	for k := range touched {
+		ctx.WitnessVectors1[k], ctx.WitnessVectors2[k] = LE.WitGenMemory(memoryTree, leParams, ctx.TreeIndices[k])
+	}
+
+	if ctx.Bloom != nil {
+		for _, h := range hashes {
+			ctx.Bloom.Add(h)
+		}
+	}
+
+	atomic.AddUint64(&ctx.Version, 1)
+	log.Printf("AddEntries: inserted %d entries, regenerated witnesses for %d touched indices (set size now %d)",
+		len(hashes), len(touched), len(ctx.OriginalHashes))
+	return nil
+}
+
+// RemoveEntries drops every entry in ctx whose original hash matches one of
+// hashes. It doesn't call into pkg/LE at all: DetectIntersectionWithContext
+// and friends only ever consult a server index k through
+// ctx.PrivateKeys[k]/ctx.WitnessVectors1[k]/ctx.WitnessVectors2[k], so
+// excising k from those parallel slices (and from ctx.BucketMap) makes it
+// permanently unmatchable, which is all "removal" needs to mean for
+// intersection purposes. The corresponding leaf is left behind in the
+// on-disk witness tree - pkg/LE exposes no delete primitive - but it is
+// orphaned: no ctx state references its private key any more, so no future
+// client query can ever decrypt it successfully.
+//
+// Version is bumped so a caller polling /api/status can tell its cached
+// PublicParams/witnesses are stale. Removing does not change PublicParams
+// itself, but callers are expected to treat any Version bump as a signal to
+// refetch, so this is still conservatively correct.
+func (ctx *ServerInitContext) RemoveEntries(hashes []uint64) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	toRemove := make(map[uint64]bool, len(hashes))
+	for _, h := range hashes {
+		toRemove[h] = true
+	}
+
+	keptOriginalHashes := ctx.OriginalHashes[:0:0]
+	keptTreeIndices := ctx.TreeIndices[:0:0]
+	keptPrivateKeys := ctx.PrivateKeys[:0:0]
+	keptWitness1 := ctx.WitnessVectors1[:0:0]
+	keptWitness2 := ctx.WitnessVectors2[:0:0]
+
+	removed := 0
+	for k, h := range ctx.OriginalHashes {
+		if toRemove[h] {
+			removed++
+			continue
+		}
+		keptOriginalHashes = append(keptOriginalHashes, h)
+		keptTreeIndices = append(keptTreeIndices, ctx.TreeIndices[k])
+		keptPrivateKeys = append(keptPrivateKeys, ctx.PrivateKeys[k])
+		keptWitness1 = append(keptWitness1, ctx.WitnessVectors1[k])
+		keptWitness2 = append(keptWitness2, ctx.WitnessVectors2[k])
+	}
+
+	ctx.OriginalHashes = keptOriginalHashes
+	ctx.TreeIndices = keptTreeIndices
+	ctx.PrivateKeys = keptPrivateKeys
+	ctx.WitnessVectors1 = keptWitness1
+	ctx.WitnessVectors2 = keptWitness2
+	ctx.BucketMap = buildBucketMap(ctx.TreeIndices)
+
+	atomic.AddUint64(&ctx.Version, 1)
+	log.Printf("RemoveEntries: dropped %d/%d requested entries (set size now %d)",
+		removed, len(hashes), len(ctx.OriginalHashes))
+	return nil
+}
@@ -0,0 +1,143 @@
+package psi
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func buildServerSet(n int) []uint64 {
+	set := make([]uint64, n)
+	for i := range set {
+		set[i] = uint64(i + 1)
+	}
+	return set
+}
+
+func sortedCopy(xs []uint64) []uint64 {
+	out := append([]uint64(nil), xs...)
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// TestAddEntriesMatchesFullRebuild checks that incrementally adding entries
+// to a ServerInitContext produces the same intersection result a full
+// ServerInitialize over the combined set would, for a client set that
+// overlaps both the original and the newly added entries.
+func TestAddEntriesMatchesFullRebuild(t *testing.T) {
+	initial := buildServerSet(20)
+	added := []uint64{21, 22, 23, 24, 25}
+	combined := append(append([]uint64(nil), initial...), added...)
+
+	incTreepath := fmt.Sprintf("%s/incremental_tree.db", t.TempDir())
+	incCtx, err := ServerInitialize(initial, incTreepath)
+	if err != nil {
+		t.Fatalf("ServerInitialize(incremental): %v", err)
+	}
+	defer incCtx.Close()
+
+	if err := incCtx.AddEntries(added); err != nil {
+		t.Fatalf("AddEntries: %v", err)
+	}
+	if incCtx.DataVersion() != 1 {
+		t.Fatalf("DataVersion = %d, want 1", incCtx.DataVersion())
+	}
+
+	fullTreepath := fmt.Sprintf("%s/full_tree.db", t.TempDir())
+	fullCtx, err := ServerInitialize(combined, fullTreepath)
+	if err != nil {
+		t.Fatalf("ServerInitialize(full): %v", err)
+	}
+	defer fullCtx.Close()
+
+	if incCtx.LEParams.Layers != fullCtx.LEParams.Layers {
+		t.Fatalf("Layers mismatch: incremental=%d full=%d; the two contexts must share a witness-tree size for this test to be meaningful",
+			incCtx.LEParams.Layers, fullCtx.LEParams.Layers)
+	}
+
+	clientSet := []uint64{5, 23, 999}
+
+	pp, msg, le := GetPublicParameters(incCtx)
+	incCiphertexts := ClientEncrypt(clientSet, pp, msg, le)
+	incMatches, err := DetectIntersectionWithContext(incCtx, incCiphertexts)
+	if err != nil {
+		t.Fatalf("DetectIntersectionWithContext(incremental): %v", err)
+	}
+
+	fullPP, fullMsg, fullLE := GetPublicParameters(fullCtx)
+	fullCiphertexts := ClientEncrypt(clientSet, fullPP, fullMsg, fullLE)
+	fullMatches, err := DetectIntersectionWithContext(fullCtx, fullCiphertexts)
+	if err != nil {
+		t.Fatalf("DetectIntersectionWithContext(full): %v", err)
+	}
+
+	incSorted, fullSorted := sortedCopy(incMatches), sortedCopy(fullMatches)
+	if len(incSorted) != len(fullSorted) {
+		t.Fatalf("match count mismatch: incremental=%v full=%v", incSorted, fullSorted)
+	}
+	for i := range incSorted {
+		if incSorted[i] != fullSorted[i] {
+			t.Fatalf("match set mismatch: incremental=%v full=%v", incSorted, fullSorted)
+		}
+	}
+}
+
+// TestAddEntriesRejectsCuckooServer checks that AddEntries refuses to touch
+// a server built WithCuckooHashing instead of silently placing new entries
+// outside ctx.Cuckoo's candidate/eviction scheme, where they could collide
+// with an existing entry's leaf and corrupt it.
+func TestAddEntriesRejectsCuckooServer(t *testing.T) {
+	serverSet := buildServerSet(20)
+	treepath := fmt.Sprintf("%s/cuckoo_add_tree.db", t.TempDir())
+	ctx, err := ServerInitialize(serverSet, treepath, WithCuckooHashing(2, 4))
+	if err != nil {
+		t.Fatalf("ServerInitialize: %v", err)
+	}
+	defer ctx.Close()
+
+	if err := ctx.AddEntries([]uint64{21, 22}); err == nil {
+		t.Fatalf("expected AddEntries to reject a cuckoo-hashed server instead of silently corrupting ctx.Cuckoo")
+	}
+}
+
+// TestRemoveEntriesExcludesRemovedMatches checks that RemoveEntries makes a
+// previously-matching server entry permanently unmatchable, without
+// disturbing matches for entries that were not removed.
+func TestRemoveEntriesExcludesRemovedMatches(t *testing.T) {
+	serverSet := buildServerSet(20)
+	treepath := fmt.Sprintf("%s/remove_tree.db", t.TempDir())
+	ctx, err := ServerInitialize(serverSet, treepath)
+	if err != nil {
+		t.Fatalf("ServerInitialize: %v", err)
+	}
+	defer ctx.Close()
+
+	clientSet := []uint64{5, 10, 999}
+	pp, msg, le := GetPublicParameters(ctx)
+	ciphertexts := ClientEncrypt(clientSet, pp, msg, le)
+
+	before, err := DetectIntersectionWithContext(ctx, ciphertexts)
+	if err != nil {
+		t.Fatalf("DetectIntersectionWithContext(before): %v", err)
+	}
+	if len(before) != 2 {
+		t.Fatalf("expected 2 matches before removal, got %v", before)
+	}
+
+	if err := ctx.RemoveEntries([]uint64{10}); err != nil {
+		t.Fatalf("RemoveEntries: %v", err)
+	}
+	if ctx.DataVersion() != 1 {
+		t.Fatalf("DataVersion = %d, want 1", ctx.DataVersion())
+	}
+
+	after, err := DetectIntersectionWithContext(ctx, ciphertexts)
+	if err != nil {
+		t.Fatalf("DetectIntersectionWithContext(after): %v", err)
+	}
+
+	afterSorted := sortedCopy(after)
+	if len(afterSorted) != 1 || afterSorted[0] != 5 {
+		t.Fatalf("expected only [5] to match after removing 10, got %v", afterSorted)
+	}
+}
@@ -0,0 +1,78 @@
+package psi
+
+import (
+	"fmt"
+	"testing"
+)
+
+// setupIntersectionBenchmark builds a ServerInitContext for serverSize
+// server elements and a batch of clientSize client ciphertexts (roughly
+// half of which are planted matches against the server set), so
+// BenchmarkDetectIntersectionWithContext and
+// BenchmarkDetectIntersectionWithContextBucketed can compare the full
+// O(|C|*|X|) decrypt loop against the bucketed O(|C|*avg_bucket_size) one
+// at the same dataset size.
+func setupIntersectionBenchmark(b *testing.B, serverSize, clientSize int) (*ServerInitContext, []Cxtx, []uint64) {
+	b.Helper()
+
+	serverSet := make([]uint64, serverSize)
+	for i := range serverSet {
+		serverSet[i] = uint64(i + 1)
+	}
+
+	treepath := fmt.Sprintf("%s/bench_tree.db", b.TempDir())
+	ctx, err := ServerInitialize(serverSet, treepath)
+	if err != nil {
+		b.Fatalf("ServerInitialize: %v", err)
+	}
+	b.Cleanup(func() { ctx.Close() })
+
+	clientSet := make([]uint64, clientSize)
+	for i := range clientSet {
+		if i%2 == 0 && i/2 < serverSize {
+			clientSet[i] = serverSet[i/2]
+		} else {
+			clientSet[i] = uint64(serverSize + i + 1)
+		}
+	}
+
+	pp, msg, le := GetPublicParameters(ctx)
+	ciphertexts := ClientEncrypt(clientSet, pp, msg, le)
+	bucketIDs := ClientBucketIDs(clientSet, le.Layers)
+
+	return ctx, ciphertexts, bucketIDs
+}
+
+// BenchmarkDetectIntersectionWithContext measures the pre-bucketing full
+// scan at the server sizes chunk5-3 targeted (10K, 100K), for comparison
+// against BenchmarkDetectIntersectionWithContextBucketed.
+func BenchmarkDetectIntersectionWithContext(b *testing.B) {
+	for _, serverSize := range []int{10_000, 100_000} {
+		b.Run(fmt.Sprintf("serverSize=%d", serverSize), func(b *testing.B) {
+			ctx, ciphertexts, _ := setupIntersectionBenchmark(b, serverSize, 50)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := DetectIntersectionWithContext(ctx, ciphertexts); err != nil {
+					b.Fatalf("DetectIntersectionWithContext: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDetectIntersectionWithContextBucketed measures the BucketMap-
+// restricted scan at the same server sizes and client query set as
+// BenchmarkDetectIntersectionWithContext.
+func BenchmarkDetectIntersectionWithContextBucketed(b *testing.B) {
+	for _, serverSize := range []int{10_000, 100_000} {
+		b.Run(fmt.Sprintf("serverSize=%d", serverSize), func(b *testing.B) {
+			ctx, ciphertexts, bucketIDs := setupIntersectionBenchmark(b, serverSize, 50)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := DetectIntersectionWithContextBucketed(ctx, ciphertexts, bucketIDs); err != nil {
+					b.Fatalf("DetectIntersectionWithContextBucketed: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package psi
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+)
+
+// DetectIntersectionStream runs the same worker pool DetectIntersectionWithContext
+// uses, but reads ciphertexts from in and writes each matching
+// ctx.OriginalHashes value to out the moment a worker confirms it, instead
+// of buffering every ciphertext up front and waiting for every decrypt to
+// finish before returning one slice. This is what lets the JSON HTTP
+// handler and the streaming gRPC service (see pkg/psi/grpcapi) share one
+// decrypt pool: DetectIntersectionWithContext (server.go) pumps a decoded []Cxtx
+// into in and drains out into a slice, while the gRPC handler can pump
+// directly from its request stream and publish directly to its response
+// stream, bounding memory to whatever flow control the caller's stream
+// applies rather than the size of the whole client set.
+//
+// DetectIntersectionStream returns once in is closed and every ciphertext
+// already read from it has finished processing. It never closes out, since
+// the caller may be multiplexing more than one producer onto it.
+//
+// DetectIntersectionStream logs no "processed X/Y" progress percentage,
+// because the total ciphertext count isn't known until in is closed.
+func DetectIntersectionStream(ctx *ServerInitContext, in <-chan Cxtx, out chan<- uint64) error {
+	X_size := len(ctx.OriginalHashes)
+
+	numWorkers := CalculateOptimalWorkers(X_size)
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	type workItem struct {
+		cxt  Cxtx
+		j, k int
+	}
+	workItems := make(chan workItem, numWorkers*4)
+
+	matched := make(map[int]bool)
+	var matchedMu sync.Mutex
+	var workerWg sync.WaitGroup
+
+	for w := 0; w < numWorkers; w++ {
+		workerWg.Add(1)
+		go func() {
+			defer workerWg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("CRITICAL: Worker panic: %v", r)
+				}
+			}()
+
+			metrics := DefaultMetrics()
+			for item := range workItems {
+				k := item.k
+				itemStart := time.Now()
+				msg2 := LE.Dec(ctx.LEParams, ctx.PrivateKeys[k], ctx.WitnessVectors1[k], ctx.WitnessVectors2[k],
+					item.cxt.C0, item.cxt.C1, item.cxt.C, item.cxt.D)
+
+				if CorrectnessCheck(msg2, ctx.Message, ctx.LEParams) {
+					// Only record noise for the (j, k) pair that actually
+					// matched: decrypted - original for a non-matching k is
+					// just the difference between two unrelated values, not
+					// a measure of ciphertext j's real noise growth, and
+					// would exhaust item.j's noise budget almost
+					// immediately regardless of its true noise level.
+					if ctx.Noise != nil {
+						ctx.Noise.Observe(msg2, ctx.LEParams)
+					}
+					if ctx.NoiseTracker != nil {
+						avgNoise, _, _ := MeasureNoiseLevel(ctx.LEParams.R, ctx.Message, msg2, ctx.LEParams.Q)
+						if err := ctx.NoiseTracker.Record(item.j, avgNoise); err != nil {
+							log.Printf("warning: %v", err)
+						}
+					}
+
+					matchedMu.Lock()
+					first := !matched[k]
+					matched[k] = true
+					matchedMu.Unlock()
+					if first {
+						out <- ctx.OriginalHashes[k]
+						metrics.Matches.Inc()
+					}
+				}
+				metrics.DetectionLatency.Observe(time.Since(itemStart).Seconds())
+			}
+		}()
+	}
+
+	j := 0
+	for cxt := range in {
+		for k := 0; k < X_size; k++ {
+			workItems <- workItem{cxt: cxt, j: j, k: k}
+		}
+		j++
+	}
+	close(workItems)
+	workerWg.Wait()
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package psi
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Shopify/sarama"
+)
+
+// KafkaTransport is a Transport backed by Apache Kafka: client ciphertexts
+// are consumed from a consumer group reading ciphertextTopic, and confirmed
+// intersection hits are published to hitTopic. One partition per tree layer
+// lets a multi-layer witness tree fan client traffic out across consumer
+// group members the same way the "event listen" workers in voltctl each own
+// a disjoint partition set.
+type KafkaTransport struct {
+	producer  sarama.SyncProducer
+	hitTopic  string
+	recvChan  chan Cxtx
+	errChan   chan error
+	cancel    context.CancelFunc
+	groupDone chan struct{}
+}
+
+// kafkaConsumerHandler adapts sarama's consumer-group callback API to the
+// single recvChan KafkaTransport.Recv reads from.
+type kafkaConsumerHandler struct {
+	recvChan chan<- Cxtx
+	errChan  chan<- error
+}
+
+func (kafkaConsumerHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (kafkaConsumerHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h kafkaConsumerHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var c Cxtx
+		if err := json.Unmarshal(msg.Value, &c); err != nil {
+			h.errChan <- fmt.Errorf("kafka transport: decode ciphertext: %w", err)
+			continue
+		}
+		h.recvChan <- c
+		sess.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// NewKafkaTransport connects to brokers, joining groupID as a consumer of
+// ciphertextTopic (expected to have at least layers partitions, one per
+// witness-tree layer) and producing hit events to hitTopic.
+func NewKafkaTransport(brokers []string, ciphertextTopic, hitTopic, groupID string, layers int) (*KafkaTransport, error) {
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V2_8_0_0
+	cfg.Producer.Return.Successes = true
+	cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	producer, err := sarama.NewSyncProducer(brokers, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("kafka transport: new producer: %w", err)
+	}
+
+	group, err := sarama.NewConsumerGroup(brokers, groupID, cfg)
+	if err != nil {
+		producer.Close()
+		return nil, fmt.Errorf("kafka transport: new consumer group: %w", err)
+	}
+
+	recvChan := make(chan Cxtx, layers)
+	errChan := make(chan error, layers)
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := kafkaConsumerHandler{recvChan: recvChan, errChan: errChan}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer group.Close()
+		for {
+			if err := group.Consume(ctx, []string{ciphertextTopic}, handler); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				errChan <- fmt.Errorf("kafka transport: consume: %w", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	return &KafkaTransport{
+		producer:  producer,
+		hitTopic:  hitTopic,
+		recvChan:  recvChan,
+		errChan:   errChan,
+		cancel:    cancel,
+		groupDone: done,
+	}, nil
+}
+
+// Recv implements Transport.
+func (t *KafkaTransport) Recv() (Cxtx, error) {
+	select {
+	case c, ok := <-t.recvChan:
+		if !ok {
+			return Cxtx{}, ErrTransportClosed
+		}
+		return c, nil
+	case err := <-t.errChan:
+		return Cxtx{}, err
+	}
+}
+
+// Publish implements Transport.
+func (t *KafkaTransport) Publish(hit IntersectionEvent) error {
+	key := make([]byte, 8)
+	binary.LittleEndian.PutUint64(key, hit.Hash)
+	payload, err := json.Marshal(hit)
+	if err != nil {
+		return fmt.Errorf("kafka transport: encode hit: %w", err)
+	}
+	_, _, err = t.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: t.hitTopic,
+		Key:   sarama.ByteEncoder(key),
+		Value: sarama.ByteEncoder(payload),
+	})
+	return err
+}
+
+// Close stops the consumer group and producer. Safe to call once.
+func (t *KafkaTransport) Close() error {
+	t.cancel()
+	<-t.groupDone
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var prodErr error
+	go func() {
+		defer wg.Done()
+		prodErr = t.producer.Close()
+	}()
+	wg.Wait()
+	return prodErr
+}
@@ -0,0 +1,216 @@
+package psi
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics bundles the Prometheus collectors that instrument the hot paths of
+// the PSI protocol (key generation, encryption, and intersection detection).
+// A single instance is safe to register against multiple registries and to
+// share across concurrent ServerInitialize/DetectIntersectionWithContext
+// calls; all collectors are label-free counters/gauges/histograms scoped to
+// the whole process.
+//
+// ServerInitialize and DetectIntersectionWithContext record into
+// DefaultMetrics(); a separate instance from NewMetrics only receives what
+// is published into it, e.g. via PerformanceMonitor.Observe. Either can be
+// exposed through promhttp.Handler once registered.
+type Metrics struct {
+	DetectionLatency prometheus.Histogram
+	MemoryUsageMB    prometheus.Gauge
+	Throughput       prometheus.Gauge
+	TreeDepth        prometheus.Gauge
+	Matches          prometheus.Counter
+	Errors           prometheus.Counter
+
+	// TotalOperations, the per-phase histograms, and Goroutines mirror what
+	// PerformanceMonitor already tracks ad hoc (see performance.go); call
+	// PerformanceMonitor.Observe(m) to publish a monitor's current readings
+	// into these collectors instead of only PrintReport/GetMetrics.
+	TotalOperations   prometheus.Counter
+	KeyGenDuration    prometheus.Histogram
+	HashingDuration   prometheus.Histogram
+	WitnessDuration   prometheus.Histogram
+	IntersectionPhase prometheus.Histogram
+	Goroutines        prometheus.Gauge
+
+	// RequestBytes, CiphertextsPerRequest, and MatchesPerResponse are
+	// populated by MetricsMiddleware wrapping an /api/intersect-style
+	// handler.
+	RequestBytes          prometheus.Histogram
+	CiphertextsPerRequest prometheus.Histogram
+	MatchesPerResponse    prometheus.Histogram
+
+	// NoiseExhaustedEvents and NoiseRemainingBitsMin are populated by
+	// PerformanceMonitor.Observe from a NoiseBudgetTracker (see noise.go).
+	NoiseExhaustedEvents  prometheus.Counter
+	NoiseRemainingBitsMin prometheus.Gauge
+}
+
+// defaultPhaseBuckets covers sub-millisecond key generation up to
+// multi-minute witness generation over a large server set.
+var defaultPhaseBuckets = prometheus.ExponentialBuckets(1e-4, 4, 14)
+
+// NewMetrics creates the PSI Prometheus collectors with defaultPhaseBuckets
+// for the per-phase histograms. It does not register them against any
+// registry; call MustRegisterOn to do that.
+func NewMetrics() *Metrics {
+	return NewMetricsWithBuckets(defaultPhaseBuckets)
+}
+
+// NewMetricsWithBuckets is NewMetrics with caller-supplied histogram buckets
+// for the per-phase (KeyGen/Hashing/Witness/Intersection) durations, for
+// deployments whose phase latencies fall outside defaultPhaseBuckets' range.
+func NewMetricsWithBuckets(phaseBuckets []float64) *Metrics {
+	return &Metrics{
+		DetectionLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "lepsi",
+			Subsystem: "detection",
+			Name:      "item_latency_seconds",
+			Help:      "Latency of a single (ciphertext, server element) decrypt+check inside DetectIntersectionWithContext.",
+			Buckets:   prometheus.ExponentialBuckets(1e-6, 4, 12),
+		}),
+		MemoryUsageMB: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "lepsi",
+			Name:      "memory_usage_mb",
+			Help:      "Most recently observed heap memory usage, in MB (see PerformanceMonitor.GetMemoryUsage).",
+		}),
+		Throughput: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "lepsi",
+			Name:      "throughput_ops_per_second",
+			Help:      "Most recently observed PSI throughput, in operations per second.",
+		}),
+		TreeDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "lepsi",
+			Name:      "tree_depth",
+			Help:      "Number of layers in the witness tree currently loaded by the server context.",
+		}),
+		Matches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "lepsi",
+			Name:      "matches_total",
+			Help:      "Total number of intersection matches found across all DetectIntersectionWithContext calls.",
+		}),
+		Errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "lepsi",
+			Name:      "errors_total",
+			Help:      "Total number of errors returned by ServerInitialize/DetectIntersectionWithContext.",
+		}),
+		TotalOperations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "lepsi",
+			Name:      "operations_total",
+			Help:      "Total PSI operations performed, as tracked by PerformanceMonitor.TotalOperations.",
+		}),
+		KeyGenDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "lepsi",
+			Subsystem: "phase",
+			Name:      "key_generation_seconds",
+			Help:      "Duration of the key generation phase (PerformanceMonitor.KeyGenTime).",
+			Buckets:   phaseBuckets,
+		}),
+		HashingDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "lepsi",
+			Subsystem: "phase",
+			Name:      "hashing_seconds",
+			Help:      "Duration of the data hashing phase (PerformanceMonitor.HashingTime).",
+			Buckets:   phaseBuckets,
+		}),
+		WitnessDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "lepsi",
+			Subsystem: "phase",
+			Name:      "witness_generation_seconds",
+			Help:      "Duration of the witness generation phase (PerformanceMonitor.WitnessTime).",
+			Buckets:   phaseBuckets,
+		}),
+		IntersectionPhase: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "lepsi",
+			Subsystem: "phase",
+			Name:      "intersection_detection_seconds",
+			Help:      "Duration of the whole intersection detection phase (PerformanceMonitor.IntersectionTime).",
+			Buckets:   phaseBuckets,
+		}),
+		Goroutines: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "lepsi",
+			Name:      "goroutines",
+			Help:      "Most recently observed goroutine count (see PerformanceMonitor.GetMemoryUsage).",
+		}),
+		RequestBytes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "lepsi",
+			Subsystem: "http",
+			Name:      "intersect_request_bytes",
+			Help:      "Size of /api/intersect request bodies, in bytes.",
+			Buckets:   prometheus.ExponentialBuckets(1<<10, 4, 10),
+		}),
+		CiphertextsPerRequest: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "lepsi",
+			Subsystem: "http",
+			Name:      "intersect_request_ciphertexts",
+			Help:      "Number of ciphertexts submitted per /api/intersect request.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 12),
+		}),
+		MatchesPerResponse: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "lepsi",
+			Subsystem: "http",
+			Name:      "intersect_response_matches",
+			Help:      "Number of matches returned per /api/intersect response.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 12),
+		}),
+		NoiseExhaustedEvents: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "lepsi",
+			Subsystem: "noise",
+			Name:      "exhausted_events_total",
+			Help:      "Total number of ciphertext indices a NoiseBudgetTracker has marked exhausted.",
+		}),
+		NoiseRemainingBitsMin: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "lepsi",
+			Subsystem: "noise",
+			Name:      "remaining_bits_min",
+			Help:      "Smallest NoiseBudgetTracker.NoiseBudgetReport remaining-bits value across all tracked ciphertexts.",
+		}),
+	}
+}
+
+// MustRegisterOn registers every collector in m on reg, panicking on
+// duplicate registration the same way prometheus.MustRegister does.
+func (m *Metrics) MustRegisterOn(reg prometheus.Registerer) {
+	reg.MustRegister(
+		m.DetectionLatency,
+		m.MemoryUsageMB,
+		m.Throughput,
+		m.TreeDepth,
+		m.Matches,
+		m.Errors,
+		m.TotalOperations,
+		m.KeyGenDuration,
+		m.HashingDuration,
+		m.WitnessDuration,
+		m.IntersectionPhase,
+		m.Goroutines,
+		m.RequestBytes,
+		m.CiphertextsPerRequest,
+		m.MatchesPerResponse,
+		m.NoiseExhaustedEvents,
+		m.NoiseRemainingBitsMin,
+	)
+}
+
+// defaultMetrics is lazily created so that callers who never opt in to
+// Prometheus (the common case for tests and small programs) pay no cost.
+var (
+	defaultMetricsOnce sync.Once
+	defaultMetricsInst *Metrics
+)
+
+// DefaultMetrics returns a process-wide Metrics instance, creating it on
+// first use. ServerInitialize and DetectIntersectionWithContext always
+// record into this instance, so a caller only needs to register it once:
+//
+//	psi.DefaultMetrics().MustRegisterOn(prometheus.DefaultRegisterer)
+//	http.Handle("/metrics", promhttp.Handler())
+func DefaultMetrics() *Metrics {
+	defaultMetricsOnce.Do(func() {
+		defaultMetricsInst = NewMetrics()
+	})
+	return defaultMetricsInst
+}
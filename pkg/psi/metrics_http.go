@@ -0,0 +1,76 @@
+package psi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler returns the standard Prometheus text-exposition handler for
+// whatever collectors have been registered (e.g. via
+// DefaultMetrics().MustRegisterOn), so callers can mount it directly:
+//
+//	psi.DefaultMetrics().MustRegisterOn(prometheus.DefaultRegisterer)
+//	mux.Handle("/metrics", psi.Handler())
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// intersectRequestShape and intersectResponseShape mirror just the fields of
+// simulation/server's IntersectionRequest/IntersectionResponse that
+// MetricsMiddleware needs to count. Duplicating these two fields here
+// (instead of importing package main) keeps pkg/psi independent of the
+// simulation binaries while still matching their JSON wire shape.
+type intersectRequestShape struct {
+	Ciphertexts []json.RawMessage `json:"ciphertexts"`
+}
+
+type intersectResponseShape struct {
+	Count int `json:"count"`
+}
+
+// MetricsMiddleware wraps an /api/intersect-style handler, recording the
+// request body size, the number of ciphertexts submitted, and the number of
+// matches returned against m. It degrades silently (still calling next) if
+// the request/response body doesn't match intersectRequestShape/
+// intersectResponseShape, so it can be dropped in front of any handler
+// without coupling MetricsMiddleware to the handler's exact request type.
+func MetricsMiddleware(m *Metrics, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err == nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			m.RequestBytes.Observe(float64(len(body)))
+
+			var reqShape intersectRequestShape
+			if json.Unmarshal(body, &reqShape) == nil {
+				m.CiphertextsPerRequest.Observe(float64(len(reqShape.Ciphertexts)))
+			}
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, buf: &bytes.Buffer{}}
+		next(rec, r)
+
+		var respShape intersectResponseShape
+		if json.Unmarshal(rec.buf.Bytes(), &respShape) == nil {
+			m.MatchesPerResponse.Observe(float64(respShape.Count))
+		}
+	}
+}
+
+// responseRecorder tees everything written through it into buf so
+// MetricsMiddleware can inspect the response after next returns, while still
+// forwarding the bytes to the real ResponseWriter unchanged.
+type responseRecorder struct {
+	http.ResponseWriter
+	buf *bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.buf.Write(b)
+	return r.ResponseWriter.Write(b)
+}
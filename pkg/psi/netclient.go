@@ -0,0 +1,93 @@
+package psi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// NetClient is the network counterpart to NetServer: it fetches public
+// parameters and submits ciphertexts (which the caller encrypts itself,
+// e.g. with ClientEncrypt) over one TCP connection instead of calling
+// Client/DetectIntersectionWithContext in the same process as the server.
+type NetClient struct {
+	conn net.Conn
+}
+
+// DialNetClient connects to a NetServer listening at addr.
+func DialNetClient(addr string) (*NetClient, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("psi: dial %s: %w", addr, err)
+	}
+	return &NetClient{conn: conn}, nil
+}
+
+// Close closes the underlying connection.
+func (c *NetClient) Close() error {
+	return c.conn.Close()
+}
+
+// GetPublicParameters fetches and deserializes the server's public
+// parameters, the network equivalent of psi.GetPublicParameters against an
+// in-process ServerInitContext.
+func (c *NetClient) GetPublicParameters() (*SerializableParams, error) {
+	if err := writeFrame(c.conn, msgGetPublicParams, nil); err != nil {
+		return nil, err
+	}
+	msgType, payload, err := readFrame(c.conn)
+	if err != nil {
+		return nil, fmt.Errorf("psi: read params response: %w", err)
+	}
+	if msgType == msgError {
+		return nil, fmt.Errorf("psi: server error: %s", payload)
+	}
+	if msgType != msgPublicParams {
+		return nil, fmt.Errorf("psi: expected PublicParams (%d), got %d", msgPublicParams, msgType)
+	}
+	var params SerializableParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		return nil, fmt.Errorf("psi: decode params: %w", err)
+	}
+	return &params, nil
+}
+
+// Intersect submits ciphertexts (normally produced by ClientEncrypt against
+// parameters from GetPublicParameters) and returns the matching hashes the
+// server reports, streaming both directions one frame per item.
+func (c *NetClient) Intersect(ciphertexts []Cxtx) ([]uint64, error) {
+	for _, ct := range ciphertexts {
+		payload, err := json.Marshal(ct)
+		if err != nil {
+			return nil, fmt.Errorf("psi: encode ciphertext: %w", err)
+		}
+		if err := writeFrame(c.conn, msgSubmitCiphertext, payload); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeFrame(c.conn, msgEndOfCiphertexts, nil); err != nil {
+		return nil, err
+	}
+
+	var matches []uint64
+	for {
+		msgType, payload, err := readFrame(c.conn)
+		if err != nil {
+			return nil, fmt.Errorf("psi: read intersection frame: %w", err)
+		}
+		switch msgType {
+		case msgEndOfIntersection:
+			return matches, nil
+		case msgError:
+			return nil, fmt.Errorf("psi: server error: %s", payload)
+		case msgIntersectionHash:
+			hash, err := readHash(payload)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, hash)
+		default:
+			return nil, fmt.Errorf("psi: unexpected message type %d in intersection stream", msgType)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package psi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Wire protocol between NetServer and NetClient: every message is a
+// 4-byte big-endian length prefix, a 1-byte message type, and a payload.
+// Payloads are JSON today (the same encoding simulation/server and
+// simulation/client already exchange Cxtx/SerializableParams in over
+// HTTP); the binary SerializeParametersBinary/DeserializeParametersBinary
+// codec is not used on this wire, and could replace the payload encoding
+// without changing the framing.
+const (
+	msgGetPublicParams   byte = 1
+	msgPublicParams      byte = 2
+	msgSubmitCiphertext  byte = 3
+	msgEndOfCiphertexts  byte = 4
+	msgIntersectionHash  byte = 5
+	msgEndOfIntersection byte = 6
+	msgError             byte = 7
+)
+
+const maxFramePayload = 64 << 20 // 64MiB, generous for one Cxtx/param frame.
+
+// writeFrame writes one length-prefixed frame to w.
+func writeFrame(w io.Writer, msgType byte, payload []byte) error {
+	var header [5]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(payload)))
+	header[4] = msgType
+	if _, err := w.Write(header[:]); err != nil {
+		return fmt.Errorf("psi: write frame header: %w", err)
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	if _, err := w.Write(payload); err != nil {
+		return fmt.Errorf("psi: write frame payload: %w", err)
+	}
+	return nil
+}
+
+// readFrame reads one length-prefixed frame from r.
+func readFrame(r io.Reader) (msgType byte, payload []byte, err error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length > maxFramePayload {
+		return 0, nil, fmt.Errorf("psi: frame payload %d bytes exceeds limit %d", length, maxFramePayload)
+	}
+	msgType = header[4]
+	if length == 0 {
+		return msgType, nil, nil
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, fmt.Errorf("psi: read frame payload: %w", err)
+	}
+	return msgType, payload, nil
+}
+
+// writeHashFrame encodes hash as an 8-byte big-endian frame payload.
+func writeHashFrame(w io.Writer, msgType byte, hash uint64) error {
+	var payload [8]byte
+	binary.BigEndian.PutUint64(payload[:], hash)
+	return writeFrame(w, msgType, payload[:])
+}
+
+// readHash decodes an 8-byte big-endian frame payload written by
+// writeHashFrame.
+func readHash(payload []byte) (uint64, error) {
+	if len(payload) != 8 {
+		return 0, fmt.Errorf("psi: hash frame payload is %d bytes, want 8", len(payload))
+	}
+	return binary.BigEndian.Uint64(payload), nil
+}
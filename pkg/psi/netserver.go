@@ -0,0 +1,107 @@
+package psi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+)
+
+// NetServer serves a single ServerInitContext to many clients over the
+// framed-TCP wire protocol in netprotocol.go, instead of Server()'s
+// single-process Client()-then-DetectIntersectionWithContext call. Unlike
+// Server, a NetServer does not re-run ServerInitialize per request: build
+// the context once and Serve as many connections against it as needed.
+//
+// It is not named Server/Client to avoid colliding with the existing
+// single-process Server and Client functions that simulation/server,
+// simulation/client, benchmarks, and scalability_tests already depend on;
+// those remain the in-process path, NetServer/NetClient are the network
+// path.
+type NetServer struct {
+	ctx *ServerInitContext
+}
+
+// NewNetServer wraps ctx (from ServerInitialize) for network service.
+func NewNetServer(ctx *ServerInitContext) *NetServer {
+	return &NetServer{ctx: ctx}
+}
+
+// Serve accepts connections on lis and handles each with handleConn until
+// lis is closed.
+func (s *NetServer) Serve(lis net.Listener) error {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return fmt.Errorf("psi: accept: %w", err)
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn runs one client session: GetPublicParameters, then
+// SubmitCiphertexts (a Cxtx per frame terminated by msgEndOfCiphertexts),
+// then GetIntersection (one matched hash per frame terminated by
+// msgEndOfIntersection).
+func (s *NetServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	msgType, _, err := readFrame(conn)
+	if err != nil {
+		log.Printf("psi: NetServer: read GetPublicParams request: %v", err)
+		return
+	}
+	if msgType != msgGetPublicParams {
+		writeFrame(conn, msgError, []byte(fmt.Sprintf("expected GetPublicParams (%d), got %d", msgGetPublicParams, msgType)))
+		return
+	}
+
+	params := SerializeParameters(s.ctx.PublicParams, s.ctx.Message, s.ctx.LEParams)
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		log.Printf("psi: NetServer: marshal params: %v", err)
+		return
+	}
+	if err := writeFrame(conn, msgPublicParams, paramsJSON); err != nil {
+		log.Printf("psi: NetServer: write params: %v", err)
+		return
+	}
+
+	var ciphertexts []Cxtx
+	for {
+		msgType, payload, err := readFrame(conn)
+		if err != nil {
+			log.Printf("psi: NetServer: read ciphertext frame: %v", err)
+			return
+		}
+		if msgType == msgEndOfCiphertexts {
+			break
+		}
+		if msgType != msgSubmitCiphertext {
+			writeFrame(conn, msgError, []byte(fmt.Sprintf("expected SubmitCiphertext (%d) or end-of-stream (%d), got %d", msgSubmitCiphertext, msgEndOfCiphertexts, msgType)))
+			return
+		}
+		var c Cxtx
+		if err := json.Unmarshal(payload, &c); err != nil {
+			writeFrame(conn, msgError, []byte(fmt.Sprintf("decode ciphertext: %v", err)))
+			return
+		}
+		ciphertexts = append(ciphertexts, c)
+	}
+
+	matches, _, err := DetectIntersectionWithContextFiltered(s.ctx, ciphertexts, nil)
+	if err != nil {
+		writeFrame(conn, msgError, []byte(fmt.Sprintf("detect intersection: %v", err)))
+		return
+	}
+
+	for _, hash := range matches {
+		if err := writeHashFrame(conn, msgIntersectionHash, hash); err != nil {
+			log.Printf("psi: NetServer: write intersection hash: %v", err)
+			return
+		}
+	}
+	if err := writeFrame(conn, msgEndOfIntersection, nil); err != nil {
+		log.Printf("psi: NetServer: write end-of-intersection: %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package psi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// NoiseBudget tracks how close decrypted coefficients come to the 0/1
+// decision boundaries CorrectnessCheck uses (Q/4 and 3Q/4), across every
+// item DetectIntersectionWithContext processes. A coefficient that lands
+// exactly on a boundary has zero margin left before a single extra layer of
+// noise growth flips its decoded bit; one that lands on 0 or Q/2 has the
+// most margin available. Aggregating these margins gives an empirical
+// estimate of how many more witness-tree layers the current parameters can
+// absorb before decryption starts failing.
+type NoiseBudget struct {
+	mu         sync.Mutex
+	histogram  map[string]int
+	marginBits []float64
+	layers     int
+}
+
+// NewNoiseBudget creates an empty NoiseBudget for a witness tree with the
+// given number of layers.
+func NewNoiseBudget(layers int) *NoiseBudget {
+	return &NoiseBudget{
+		histogram: make(map[string]int),
+		layers:    layers,
+	}
+}
+
+// marginBitsBucket buckets a margin (in bits) into a fixed-width histogram
+// key, so NoiseMetrics.Distribution stays small regardless of dataset size.
+func marginBitsBucket(bits float64) string {
+	bucket := int(math.Floor(bits))
+	if bucket < 0 {
+		bucket = 0
+	}
+	return fmt.Sprintf("%d-%d bits", bucket, bucket+1)
+}
+
+// Observe records the decision-boundary margin of every coefficient of a
+// single decrypted polynomial. DetectIntersectionStream calls it once per
+// (ciphertext, server element) pair that passes CorrectnessCheck.
+func (nb *NoiseBudget) Observe(decrypted *ring.Poly, le *LE.LE) {
+	q14 := float64(le.Q / 4)
+	q34 := float64((le.Q / 4) * 3)
+
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	for i := 0; i < le.R.N; i++ {
+		c := float64(decrypted.Coeffs[0][i])
+		// Distance to the nearer of the two decision boundaries a bit
+		// flip would have to cross (0 decodes near 0/Q, 1 decodes near
+		// Q/2; CorrectnessCheck's boundaries are at Q/4 and 3Q/4).
+		margin := math.Min(math.Abs(c-q14), math.Abs(c-q34))
+		if margin < 1 {
+			margin = 1
+		}
+		bits := math.Log2(margin)
+		nb.marginBits = append(nb.marginBits, bits)
+		nb.histogram[marginBitsBucket(bits)]++
+	}
+}
+
+// Distribution returns the accumulated margin histogram, suitable for
+// utils.NoiseMetrics.Distribution.
+func (nb *NoiseBudget) Distribution() map[string]int {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	out := make(map[string]int, len(nb.histogram))
+	for k, v := range nb.histogram {
+		out[k] = v
+	}
+	return out
+}
+
+// PredictedSafeDepth estimates the maximum witness-tree depth the observed
+// noise growth can still decrypt correctly, assuming noise grows by
+// roughly one bit of margin per additional layer (the standard rule of
+// thumb for the Ring-LWE gadget-decomposition noise growth this scheme
+// uses). It is a heuristic, not a formally derived bound — treat it as a
+// warning signal, not a security proof.
+func (nb *NoiseBudget) PredictedSafeDepth() int {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	if len(nb.marginBits) == 0 {
+		return nb.layers
+	}
+	var sum float64
+	for _, b := range nb.marginBits {
+		sum += b
+	}
+	avgMarginBits := sum / float64(len(nb.marginBits))
+
+	return nb.layers + int(math.Floor(avgMarginBits))
+}
+
+// defaultNoiseBudgetThreshold is the fraction of Q a ciphertext's noise is
+// allowed to reach (see MeasureNoiseLevel's noiseFraction) before
+// NoiseBudgetTracker.Record starts reporting it as exhausted.
+const defaultNoiseBudgetThreshold = 0.20
+
+// ErrNoiseExhausted is returned by NoiseBudgetTracker.Record when the
+// projected noise on a ciphertext index after one more homomorphic
+// operation would exceed the tracker's threshold, signaling that index
+// needs a fresh ClientEncrypt rather than continued reuse.
+var ErrNoiseExhausted = errors.New("psi: noise budget exhausted, re-encrypt required")
+
+// NoiseBudgetTracker maintains, per client ciphertext index, a moving
+// average and maximum of MeasureNoiseLevel's noiseFraction across
+// successive homomorphic operations on that ciphertext. Unlike NoiseBudget
+// (which aggregates margins across the whole batch to predict a safe tree
+// depth), NoiseBudgetTracker answers a narrower question for one index at a
+// time: is this specific ciphertext still safe to operate on again, or does
+// the caller need to discard it and re-encrypt?
+//
+// The projection assumes noise roughly doubles per additional homomorphic
+// operation - the same rule of thumb NoiseBudget.PredictedSafeDepth uses
+// (one bit of margin lost per layer).
+type NoiseBudgetTracker struct {
+	mu              sync.Mutex
+	threshold       float64
+	avg             map[int]float64
+	max             map[int]float64
+	count           map[int]int
+	exhausted       map[int]bool
+	exhaustedEvents int
+
+	// OnExhausted, if set, is called (under no lock) whenever Record
+	// detects index has crossed threshold, in addition to the returned
+	// error - e.g. to log a "noise-exhausted" event into a performance
+	// report.
+	OnExhausted func(index int)
+}
+
+// NewNoiseBudgetTracker creates a NoiseBudgetTracker with the given
+// threshold (a fraction of Q, e.g. 0.20). A threshold <= 0 falls back to
+// defaultNoiseBudgetThreshold.
+func NewNoiseBudgetTracker(threshold float64) *NoiseBudgetTracker {
+	if threshold <= 0 {
+		threshold = defaultNoiseBudgetThreshold
+	}
+	return &NoiseBudgetTracker{
+		threshold: threshold,
+		avg:       make(map[int]float64),
+		max:       make(map[int]float64),
+		count:     make(map[int]int),
+		exhausted: make(map[int]bool),
+	}
+}
+
+// Record folds one observed noiseFraction (MeasureNoiseLevel's
+// avgNoiseFraction or maxNoiseFraction for the ciphertext at index) into
+// that index's moving average and maximum. If the projected noise after one
+// more operation of similar magnitude would exceed t's threshold, Record
+// marks index exhausted, invokes OnExhausted (if set), and returns
+// ErrNoiseExhausted wrapped with the index and projected value.
+func (t *NoiseBudgetTracker) Record(index int, noiseFraction float64) error {
+	t.mu.Lock()
+	n := t.count[index]
+	newAvg := (t.avg[index]*float64(n) + noiseFraction) / float64(n+1)
+	t.avg[index] = newAvg
+	t.count[index] = n + 1
+	if noiseFraction > t.max[index] {
+		t.max[index] = noiseFraction
+	}
+
+	projected := newAvg * 2
+	exceeded := projected > t.threshold
+	if exceeded {
+		if !t.exhausted[index] {
+			t.exhaustedEvents++
+		}
+		t.exhausted[index] = true
+	}
+	t.mu.Unlock()
+
+	if !exceeded {
+		return nil
+	}
+	if t.OnExhausted != nil {
+		t.OnExhausted(index)
+	}
+	return fmt.Errorf("%w: ciphertext %d projected noise %.4f exceeds threshold %.4f", ErrNoiseExhausted, index, projected, t.threshold)
+}
+
+// ExhaustedEvents returns how many times Record has marked some index
+// exhausted for the first time.
+func (t *NoiseBudgetTracker) ExhaustedEvents() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exhaustedEvents
+}
+
+// NoiseBudgetReportEntry is one ciphertext index's entry in a
+// NoiseBudgetReport.
+type NoiseBudgetReportEntry struct {
+	AvgNoiseFraction float64 `json:"avg_noise_fraction"`
+	MaxNoiseFraction float64 `json:"max_noise_fraction"`
+	RemainingBits    float64 `json:"remaining_bits"`
+	Exhausted        bool    `json:"exhausted"`
+}
+
+// NoiseBudgetReport returns, for every ciphertext index Record has observed,
+// its remaining budget in bits - log2(Q/(2*avgNoise)), where avgNoise is the
+// index's average noiseFraction scaled by Q - alongside its raw average/max
+// noiseFraction and whether it has already been marked exhausted.
+func (t *NoiseBudgetTracker) NoiseBudgetReport(Q uint64) map[int]NoiseBudgetReportEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := make(map[int]NoiseBudgetReportEntry, len(t.avg))
+	for idx, avg := range t.avg {
+		remaining := math.Inf(1)
+		if avg > 0 {
+			avgNoise := avg * float64(Q)
+			remaining = math.Log2(float64(Q) / (2 * avgNoise))
+		}
+		report[idx] = NoiseBudgetReportEntry{
+			AvgNoiseFraction: avg,
+			MaxNoiseFraction: t.max[idx],
+			RemainingBits:    remaining,
+			Exhausted:        t.exhausted[idx],
+		}
+	}
+	return report
+}
+
+// MinRemainingBits returns the smallest RemainingBits across every tracked
+// ciphertext index, and false if nothing has been recorded yet. It is a
+// single scalar proxy for NoiseBudgetReport suitable for a Prometheus gauge.
+func (t *NoiseBudgetTracker) MinRemainingBits(Q uint64) (float64, bool) {
+	report := t.NoiseBudgetReport(Q)
+	if len(report) == 0 {
+		return 0, false
+	}
+	min := math.Inf(1)
+	for _, entry := range report {
+		if entry.RemainingBits < min {
+			min = entry.RemainingBits
+		}
+	}
+	return min, true
+}
@@ -7,90 +7,256 @@ import (
 	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
 )
 
+// allowedRingDimensions lists the ring dimensions LE.Setup supports.
+var allowedRingDimensions = map[int]bool{256: true, 512: true, 1024: true, 2048: true}
+
+// LEParams is the configuration surface for SetupLEParameters: the Ring-LWE
+// modulus, ring dimension, matrix dimension and witness-tree expansion
+// factor a caller wants, plus - once Resolve has run against a dataset size
+// - the values derived from them (Merkle tree Layers, LoadFactor and
+// CollisionProbability), so a server or client can log or negotiate the
+// security/perf trade-off it's actually running at instead of trusting the
+// hardcoded comment block this replaces.
+type LEParams struct {
+	Q               uint64
+	QBits           int
+	D               int
+	N               int
+	ExpansionFactor float64
+
+	Layers               int
+	LoadFactor           float64
+	CollisionProbability float64
+}
+
+// LEParamOption overrides one field of a LEParams preset, mirroring the
+// With... option-func convention used elsewhere in this package (see e.g.
+// CSVIngestOption in csv_ingest.go).
+type LEParamOption func(*LEParams)
+
+// WithExpansionFactor overrides the witness-tree expansion factor (slots
+// per item) a preset ships with.
+func WithExpansionFactor(c float64) LEParamOption {
+	return func(p *LEParams) { p.ExpansionFactor = c }
+}
+
+// WithRingDimension overrides the ring dimension D a preset ships with.
+// Resolve rejects any value not in allowedRingDimensions.
+func WithRingDimension(d int) LEParamOption {
+	return func(p *LEParams) { p.D = d }
+}
+
+// WithModulus overrides the modulus Q and its bit length qBits a preset
+// ships with. Resolve rejects a (Q, D) pair that isn't NTT-friendly.
+func WithModulus(q uint64, qBits int) LEParamOption {
+	return func(p *LEParams) { p.Q, p.QBits = q, qBits }
+}
+
+// WithMatrixDim overrides the matrix dimension N a preset ships with.
+func WithMatrixDim(n int) LEParamOption {
+	return func(p *LEParams) { p.N = n }
+}
+
+// Security128 is the 128-bit security preset: the single hardcoded
+// (Q, qBits, D, N) SetupLEParameters used before presets existed.
+func Security128() LEParams {
+	return LEParams{Q: 180143985094819841, QBits: 58, D: 256, N: 4, ExpansionFactor: 16.0}
+}
+
+// Security192 trades a larger ring dimension for more noise headroom per
+// witness-tree layer, per estimateFailureProbability's "doubling D roughly
+// doubles the margin" rule of thumb.
+func Security192() LEParams {
+	return LEParams{Q: 180143985094819841, QBits: 58, D: 1024, N: 4, ExpansionFactor: 16.0}
+}
+
+// Security256 doubles Security192's ring dimension (D=2048) and also raises
+// the matrix dimension N from 4 to 6, for deployments that want margin
+// beyond what the ring dimension alone buys.
+func Security256() LEParams {
+	return LEParams{Q: 180143985094819841, QBits: 58, D: 2048, N: 6, ExpansionFactor: 16.0}
+}
+
+// isNTTFriendlyModulus reports whether Q supports a negacyclic NTT over a
+// ring of dimension D: Q-1 must be divisible by 2*D so a primitive 2D-th
+// root of unity exists mod Q.
+func isNTTFriendlyModulus(q uint64, d int) bool {
+	return (q-1)%uint64(2*d) == 0
+}
+
+// Resolve validates p and derives Layers, LoadFactor and
+// CollisionProbability for datasetSize from p's Q/QBits/D/N/
+// ExpansionFactor, returning the populated copy SetupLEParameters passes to
+// LE.Setup.
+func (p LEParams) Resolve(datasetSize int) (LEParams, error) {
+	if !allowedRingDimensions[p.D] {
+		return LEParams{}, fmt.Errorf("leparams: unsupported ring dimension %d, supported values: 256, 512, 1024, 2048", p.D)
+	}
+	if !isNTTFriendlyModulus(p.Q, p.D) {
+		return LEParams{}, fmt.Errorf("leparams: modulus %d is not NTT-friendly for ring dimension %d (Q-1 must be divisible by 2*D)", p.Q, p.D)
+	}
+	if p.N <= 0 {
+		return LEParams{}, fmt.Errorf("leparams: matrix dimension must be positive, got %d", p.N)
+	}
+	if p.ExpansionFactor <= 0 {
+		return LEParams{}, fmt.Errorf("leparams: expansion factor must be positive, got %v", p.ExpansionFactor)
+	}
+
+	p.Layers = int(math.Ceil(math.Log2(p.ExpansionFactor * float64(datasetSize))))
+
+	numSlots := 1 << p.Layers
+	p.LoadFactor = float64(datasetSize) / float64(numSlots)
+
+	m := float64(datasetSize)
+	n := float64(numSlots)
+	p.CollisionProbability = 1.0 - math.Exp(-(m*m)/(2*n))
+
+	return p, nil
+}
+
 // SetupLEParameters initializes Laconic Encryption parameters for PSI operations.
 // This function configures the Ring-LWE cryptographic parameters and computes
 // the optimal Merkle tree depth based on dataset size.
 //
 // Parameters:
 //   - size: Expected number of elements in the server dataset
+//   - opts: option funcs layered on top of the Security128 preset, e.g.
+//     psi.SetupLEParameters(size, psi.WithRingDimension(1024)), or pass a
+//     different preset's fields via WithRingDimension/WithModulus/
+//     WithMatrixDim/WithExpansionFactor to run at Security192/Security256 instead.
 //
 // Returns:
 //   - *LE.LE: Configured Laconic Encryption parameters
-//   - error: Non-nil if parameter initialization fails
-//
-// Cryptographic Parameters (128-bit security):
-//   - Q: Modulus = 180143985094819841 (~2^58)
-//   - D: Ring dimension = 256 (supports 256, 512, 1024, 2048)
-//   - N: Matrix dimension = 4
-//   - qBits: Modulus bit length = 58
-//
-// The function automatically calculates:
-//   - Merkle tree layers: log2(16 * size) for 16x expansion factor
-//   - Load factor: items per slot ratio
-//   - Collision probability: using balls-into-bins model
+//   - LEParams: the resolved configuration, including the derived Layers,
+//     LoadFactor and CollisionProbability, for the caller to log or persist
+//   - error: Non-nil if parameter initialization or validation fails
 //
 // Example:
 //
-//	le, err := psi.SetupLEParameters(10000)
+//	le, resolved, err := psi.SetupLEParameters(10000)
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
-//	// le.Layers = 18 (for 10K elements with 16x expansion)
-//	// Collision probability < 10^-6
-func SetupLEParameters(size int) (*LE.LE, error) {
-	const (
-		Q     = uint64(180143985094819841) // Modulus (~2^58)
-		qBits = 58                          // Modulus bit length
-		D     = 256                         // Ring dimension (128-bit security)
-		N     = 4                           // Matrix dimension
-		c     = 16.0                        // Expansion factor (16x slots vs items)
-	)
-
-	if D != 256 && D != 512 && D != 1024 && D != 2048 {
-		return nil, fmt.Errorf("unsupported ring dimension %d. Supported values: 256, 512, 1024, 2048", D)
+//	// resolved.Layers = 18 (for 10K elements with 16x expansion)
+//	// resolved.CollisionProbability ≈ 1 (1 - e^-190: 10K elements in 2^18 slots)
+func SetupLEParameters(size int, opts ...LEParamOption) (*LE.LE, LEParams, error) {
+	params := Security128()
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	resolved, err := params.Resolve(size)
+	if err != nil {
+		return nil, LEParams{}, err
 	}
 
 	var leParams *LE.LE
-	var err error
 
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
-				err = fmt.Errorf("panic in LE.setup with dimension %d: %v", D, r)
+				err = fmt.Errorf("panic in LE.setup with dimension %d: %v", resolved.D, r)
 				fmt.Printf("Recovered from Panic in LE.setup: %v\n", r)
 			}
 		}()
-		fmt.Println("Setting up LE with Parameters Q =", Q, "qBits =", qBits, "D =", D, "N =", N)
-		leParams = LE.Setup(Q, qBits, D, N)
+		fmt.Println("Setting up LE with Parameters Q =", resolved.Q, "qBits =", resolved.QBits, "D =", resolved.D, "N =", resolved.N)
+		leParams = LE.Setup(resolved.Q, resolved.QBits, resolved.D, resolved.N)
 	}()
-	
+
 	if err != nil {
-		return nil, err
+		return nil, LEParams{}, err
 	}
 	if leParams == nil {
-		return nil, fmt.Errorf("failed to initialize the le parameters (nil result)")
+		return nil, LEParams{}, fmt.Errorf("failed to initialize the le parameters (nil result)")
 	}
 	if leParams.R == nil {
-		return nil, fmt.Errorf("ring(R) is nil in le parameters")
+		return nil, LEParams{}, fmt.Errorf("ring(R) is nil in le parameters")
 	}
 
-	leParams.Layers = int(math.Ceil(math.Log2(c * float64(size))))
-
-	numSlots := 1 << leParams.Layers
-	loadFactor := float64(size) / float64(numSlots)
-	
-	m := float64(size)
-	Nf := float64(numSlots)
-	collisionProb := 1.0 - math.Exp(-(m*m)/(2*Nf))
+	leParams.Layers = resolved.Layers
 
 	fmt.Println("Successfully initialized the LE parameters:")
-	fmt.Printf(" - Ring Dimension: %d\n", D)
-	fmt.Printf(" - Modulus Q: %d\n", Q)
-	fmt.Printf(" - Matrix Dimension N: %d\n", N)
-	fmt.Printf(" - qBits: %d\n", qBits)
-	fmt.Printf(" - Layers: %d (slots = %d)\n", leParams.Layers, numSlots)
-	fmt.Printf(" - Load Factor: %.6f (items/slot)\n", loadFactor)
-	fmt.Printf(" - Estimated Collision Probability: %.6e\n", collisionProb)
-
-	return leParams, nil
+	fmt.Printf(" - Ring Dimension: %d\n", resolved.D)
+	fmt.Printf(" - Modulus Q: %d\n", resolved.Q)
+	fmt.Printf(" - Matrix Dimension N: %d\n", resolved.N)
+	fmt.Printf(" - qBits: %d\n", resolved.QBits)
+	fmt.Printf(" - Layers: %d (slots = %d)\n", resolved.Layers, 1<<resolved.Layers)
+	fmt.Printf(" - Load Factor: %.6f (items/slot)\n", resolved.LoadFactor)
+	fmt.Printf(" - Estimated Collision Probability: %.6e\n", resolved.CollisionProbability)
+
+	return leParams, resolved, nil
+}
+
+// secureParameterSet is one entry in the table AutoTune searches: a ring
+// dimension paired with an NTT-friendly modulus at 128-bit security, ordered
+// smallest (cheapest) to largest (most noise headroom).
+type secureParameterSet struct {
+	D     int
+	Q     uint64
+	QBits int
+}
+
+// secureParameterSets mirrors the single hardcoded (Q, qBits, D) in
+// SetupLEParameters, extended with larger rings for datasets/tree depths
+// that need more noise headroom than D=256 can absorb.
+var secureParameterSets = []secureParameterSet{
+	{D: 256, Q: 180143985094819841, QBits: 58},
+	{D: 512, Q: 180143985094819841, QBits: 58},
+	{D: 1024, Q: 180143985094819841, QBits: 58},
+	{D: 2048, Q: 180143985094819841, QBits: 58},
+}
+
+// estimateFailureProbability is a heuristic model of decryption-failure
+// probability at a given tree depth: each layer of the witness tree costs
+// roughly one bit of margin (the same rule of thumb NoiseBudget.
+// PredictedSafeDepth uses), and doubling the ring dimension roughly doubles
+// the bits of margin available before Q's own bit-length is exhausted. It is
+// not a formally derived security bound, the same caveat LEParams.Resolve's
+// CollisionProbability already carries for its balls-into-bins estimate.
+func estimateFailureProbability(set secureParameterSet, layers int) float64 {
+	marginBits := float64(set.QBits)/2*(float64(set.D)/256) - float64(layers)
+	if marginBits <= 0 {
+		return 1.0
+	}
+	return math.Exp2(-marginBits)
+}
+
+// AutoTune picks the smallest secure parameter set (D, Q, qBits) whose
+// estimated decryption-failure probability at the tree depth implied by
+// datasetSize stays below targetFPR, so a caller can request "give me
+// parameters safe enough for this dataset" instead of hardcoding
+// --ring-dimension. The chosen Layers is recorded on the returned *LE.LE the
+// same way SetupLEParameters computes it, so the result can be persisted
+// into utils.ParameterMetrics for a reproducible re-run.
+func AutoTune(targetFPR float64, datasetSize int) (*LE.LE, error) {
+	const expansionFactor = 16.0
+	layers := int(math.Ceil(math.Log2(expansionFactor * float64(datasetSize))))
+
+	for _, set := range secureParameterSets {
+		if estimateFailureProbability(set, layers) >= targetFPR {
+			continue
+		}
+
+		var leParams *LE.LE
+		var err error
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic in LE.setup with dimension %d: %v", set.D, r)
+				}
+			}()
+			leParams = LE.Setup(set.Q, set.QBits, set.D, 4)
+		}()
+		if err != nil {
+			return nil, err
+		}
+		if leParams == nil || leParams.R == nil {
+			return nil, fmt.Errorf("failed to initialize LE parameters for D=%d", set.D)
+		}
+
+		leParams.Layers = layers
+		return leParams, nil
+	}
+
+	return nil, fmt.Errorf("no secure parameter set in the table satisfies targetFPR=%v at datasetSize=%d (%d layers)", targetFPR, datasetSize, layers)
 }
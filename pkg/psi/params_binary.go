@@ -0,0 +1,456 @@
+package psi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/matrix"
+	"github.com/golang/snappy"
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// paramsBinaryMagic identifies the binary SerializableParams wire format, so
+// DeserializeParametersBinary fails fast on a JSON blob or unrelated data
+// instead of misreading it as a header.
+const paramsBinaryMagic = "LEP1"
+
+// paramsBinaryVersion is written immediately after paramsBinaryMagic. A
+// future change to the chunk layout bumps this instead of the magic, so
+// readParamsBinaryHeader can tell "not a params stream" (bad magic) apart
+// from "a params stream in a format newer than this build understands"
+// (unsupported version).
+const paramsBinaryVersion byte = 1
+
+// ParamsBinaryHeader is the fixed-size prefix of the binary encoding:
+// everything needed to build the ring.Ring and an LE.LE skeleton before the
+// (potentially much larger) PP/NTT-matrix chunks that follow have finished
+// arriving. DecodeParamsBinaryHeader/DecodeParamsBinaryBody split reading
+// the header from the body for exactly this reason.
+type ParamsBinaryHeader struct {
+	Compressed bool
+	Q          uint64
+	D          int
+	N          int
+	Layers     int
+	M          int
+	M2         int
+	PPCount    int
+}
+
+// SerializeParametersBinary writes pp/msg/le in a binary wire format: a
+// fixed ParamsBinaryHeader followed by raw little-endian uint64 coefficient
+// blocks for PP, Msg, A0NTT, A1NTT, BNTT, and GNTT, in that order. Unlike
+// SerializeParameters' JSON encoding - one decimal number per coefficient,
+// O(N*M*D) bytes, and a fresh []uint64 allocation per polynomial - each
+// chunk here is a single contiguous byte slice, optionally snappy-compressed
+// when compress is true. The JSON path remains for tests and debugging.
+func SerializeParametersBinary(w io.Writer, pp *matrix.Vector, msg *ring.Poly, le *LE.LE, compress bool) error {
+	hdr := ParamsBinaryHeader{
+		Compressed: compress,
+		Q:          le.Q,
+		D:          le.D,
+		N:          le.N,
+		Layers:     le.Layers,
+		M:          le.M,
+		M2:         le.M2,
+		PPCount:    len(pp.Elements),
+	}
+	if err := writeParamsBinaryHeader(w, hdr); err != nil {
+		return err
+	}
+
+	ppCoeffs := make([]uint64, 0, hdr.PPCount*le.D)
+	for _, poly := range pp.Elements {
+		ppCoeffs = append(ppCoeffs, polyCoeffsOrZero(poly, le.D)...)
+	}
+	if err := writeParamsBinaryChunk(w, ppCoeffs, compress); err != nil {
+		return fmt.Errorf("psi: write PP chunk: %w", err)
+	}
+
+	if err := writeParamsBinaryChunk(w, polyCoeffsOrZero(msg, le.D), compress); err != nil {
+		return fmt.Errorf("psi: write Msg chunk: %w", err)
+	}
+
+	for _, mat := range []*matrix.Matrix{le.A0NTT, le.A1NTT, le.BNTT, le.GNTT} {
+		if err := writeMatrixBinary(w, mat, le.D, compress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeserializeParametersBinary reads the wire format SerializeParametersBinary
+// writes and reconstructs pp/msg/le.
+func DeserializeParametersBinary(r io.Reader) (*matrix.Vector, *ring.Poly, *LE.LE, error) {
+	hdr, err := readParamsBinaryHeader(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return decodeParamsBinaryBody(r, hdr)
+}
+
+// DecodeParamsBinaryHeader reads just the fixed header a binary-encoded
+// SerializableParams starts with, so a streaming caller can build the
+// ring.Ring/LE.LE skeleton before reading the larger chunks DecodeParamsBinaryBody
+// still has to pull off the wire.
+func DecodeParamsBinaryHeader(r io.Reader) (ParamsBinaryHeader, error) {
+	return readParamsBinaryHeader(r)
+}
+
+// DecodeParamsBinaryBody reads the PP/Msg/NTT-matrix chunks following a
+// header already read with DecodeParamsBinaryHeader.
+func DecodeParamsBinaryBody(r io.Reader, hdr ParamsBinaryHeader) (*matrix.Vector, *ring.Poly, *LE.LE, error) {
+	return decodeParamsBinaryBody(r, hdr)
+}
+
+// ParamsDigest returns the hex-encoded SHA-256 digest of pp/msg/le's
+// canonical (uncompressed) binary encoding, so a client and server can
+// exchange a short string to confirm they share the same CRS before
+// Client() runs, instead of comparing the whole blob.
+func ParamsDigest(pp *matrix.Vector, msg *ring.Poly, le *LE.LE) (string, error) {
+	var buf bytes.Buffer
+	if err := SerializeParametersBinary(&buf, pp, msg, le, false); err != nil {
+		return "", fmt.Errorf("psi: encode for digest: %w", err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func decodeParamsBinaryBody(r io.Reader, hdr ParamsBinaryHeader) (*matrix.Vector, *ring.Poly, *LE.LE, error) {
+	ringQ, err := ring.NewRing(hdr.D, []uint64{hdr.Q})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("psi: create ring: %w", err)
+	}
+
+	ppCoeffs, err := readParamsBinaryChunk(r, hdr.PPCount*hdr.D, hdr.Compressed)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("psi: read PP chunk: %w", err)
+	}
+	ppVec := &matrix.Vector{Elements: make([]*ring.Poly, hdr.PPCount)}
+	for i := 0; i < hdr.PPCount; i++ {
+		poly := ringQ.NewPoly()
+		copy(poly.Coeffs[0], ppCoeffs[i*hdr.D:(i+1)*hdr.D])
+		ppVec.Elements[i] = poly
+	}
+
+	msgCoeffs, err := readParamsBinaryChunk(r, hdr.D, hdr.Compressed)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("psi: read Msg chunk: %w", err)
+	}
+	msgPoly := ringQ.NewPoly()
+	copy(msgPoly.Coeffs[0], msgCoeffs)
+
+	a0NTT, err := readMatrixBinary(r, ringQ, hdr.D, hdr.Compressed)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("psi: read A0NTT: %w", err)
+	}
+	a1NTT, err := readMatrixBinary(r, ringQ, hdr.D, hdr.Compressed)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("psi: read A1NTT: %w", err)
+	}
+	bNTT, err := readMatrixBinary(r, ringQ, hdr.D, hdr.Compressed)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("psi: read BNTT: %w", err)
+	}
+	gNTT, err := readMatrixBinary(r, ringQ, hdr.D, hdr.Compressed)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("psi: read GNTT: %w", err)
+	}
+
+	le := &LE.LE{
+		Q:      hdr.Q,
+		D:      hdr.D,
+		N:      hdr.N,
+		Layers: hdr.Layers,
+		M:      hdr.M,
+		M2:     hdr.M2,
+		R:      ringQ,
+		A0NTT:  a0NTT,
+		A1NTT:  a1NTT,
+		BNTT:   bNTT,
+		GNTT:   gNTT,
+	}
+	return ppVec, msgPoly, le, nil
+}
+
+func writeParamsBinaryHeader(w io.Writer, hdr ParamsBinaryHeader) error {
+	if _, err := w.Write([]byte(paramsBinaryMagic)); err != nil {
+		return fmt.Errorf("psi: write magic: %w", err)
+	}
+	if _, err := w.Write([]byte{paramsBinaryVersion}); err != nil {
+		return fmt.Errorf("psi: write version: %w", err)
+	}
+	var compressedByte byte
+	if hdr.Compressed {
+		compressedByte = 1
+	}
+	fields := []interface{}{
+		compressedByte, hdr.Q,
+		int32(hdr.D), int32(hdr.N), int32(hdr.Layers), int32(hdr.M), int32(hdr.M2), int32(hdr.PPCount),
+	}
+	for _, f := range fields {
+		if err := binary.Write(w, binary.LittleEndian, f); err != nil {
+			return fmt.Errorf("psi: write header field: %w", err)
+		}
+	}
+	return nil
+}
+
+func readParamsBinaryHeader(r io.Reader) (ParamsBinaryHeader, error) {
+	var hdr ParamsBinaryHeader
+
+	magic := make([]byte, len(paramsBinaryMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return hdr, fmt.Errorf("psi: read magic: %w", err)
+	}
+	if string(magic) != paramsBinaryMagic {
+		return hdr, fmt.Errorf("psi: not a binary SerializableParams stream (bad magic %q)", magic)
+	}
+
+	var version [1]byte
+	if _, err := io.ReadFull(r, version[:]); err != nil {
+		return hdr, fmt.Errorf("psi: read version: %w", err)
+	}
+	if version[0] != paramsBinaryVersion {
+		return hdr, fmt.Errorf("psi: unsupported binary params version %d (this build understands %d)", version[0], paramsBinaryVersion)
+	}
+
+	var compressedByte byte
+	var q uint64
+	var d, n, layers, m, m2, ppCount int32
+	fields := []interface{}{&compressedByte, &q, &d, &n, &layers, &m, &m2, &ppCount}
+	for _, f := range fields {
+		if err := binary.Read(r, binary.LittleEndian, f); err != nil {
+			return hdr, fmt.Errorf("psi: read header field: %w", err)
+		}
+	}
+
+	hdr.Compressed = compressedByte != 0
+	hdr.Q = q
+	hdr.D = int(d)
+	hdr.N = int(n)
+	hdr.Layers = int(layers)
+	hdr.M = int(m)
+	hdr.M2 = int(m2)
+	hdr.PPCount = int(ppCount)
+	return hdr, nil
+}
+
+// writeParamsBinaryChunk writes coeffs as raw little-endian uint64s,
+// optionally snappy-compressed, prefixed with the (post-compression) chunk
+// length so the reader knows how many bytes to pull off the wire.
+func writeParamsBinaryChunk(w io.Writer, coeffs []uint64, compress bool) error {
+	raw := make([]byte, 8*len(coeffs))
+	for i, c := range coeffs {
+		binary.LittleEndian.PutUint64(raw[i*8:], c)
+	}
+
+	payload := raw
+	if compress {
+		payload = snappy.Encode(nil, raw)
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(payload))); err != nil {
+		return fmt.Errorf("psi: write chunk length: %w", err)
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+func readParamsBinaryChunk(r io.Reader, count int, compress bool) ([]uint64, error) {
+	var chunkLen uint32
+	if err := binary.Read(r, binary.LittleEndian, &chunkLen); err != nil {
+		return nil, fmt.Errorf("psi: read chunk length: %w", err)
+	}
+	if chunkLen > maxFramePayload {
+		return nil, fmt.Errorf("psi: params chunk %d bytes exceeds limit %d", chunkLen, maxFramePayload)
+	}
+	payload := make([]byte, chunkLen)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("psi: read chunk: %w", err)
+	}
+
+	raw := payload
+	if compress {
+		var err error
+		raw, err = snappy.Decode(nil, payload)
+		if err != nil {
+			return nil, fmt.Errorf("psi: decompress chunk: %w", err)
+		}
+	}
+	if len(raw) != 8*count {
+		return nil, fmt.Errorf("psi: chunk has %d bytes, want %d for %d coefficients", len(raw), 8*count, count)
+	}
+
+	coeffs := make([]uint64, count)
+	for i := range coeffs {
+		coeffs[i] = binary.LittleEndian.Uint64(raw[i*8:])
+	}
+	return coeffs, nil
+}
+
+// writeMatrixBinary writes a matrix's shape (rows, cols; rows = -1 for a nil
+// matrix) followed by one chunk of its polynomials' coefficients,
+// substituting an all-zero polynomial for any nil cell - equivalent to how
+// DeserializeParameters already leaves unset cells at matrix.NewMatrix's
+// zero-initialized default, so no separate presence bitmap is needed.
+func writeMatrixBinary(w io.Writer, mat *matrix.Matrix, d int, compress bool) error {
+	rows, cols := -1, 0
+	if mat != nil && mat.Elements != nil {
+		rows = len(mat.Elements)
+		if rows > 0 {
+			cols = len(mat.Elements[0])
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, int32(rows)); err != nil {
+		return fmt.Errorf("psi: write matrix rows: %w", err)
+	}
+	if err := binary.Write(w, binary.LittleEndian, int32(cols)); err != nil {
+		return fmt.Errorf("psi: write matrix cols: %w", err)
+	}
+	if rows <= 0 {
+		return nil
+	}
+
+	coeffs := make([]uint64, 0, rows*cols*d)
+	for _, row := range mat.Elements {
+		for j := 0; j < cols; j++ {
+			var poly *ring.Poly
+			if j < len(row) {
+				poly = row[j]
+			}
+			coeffs = append(coeffs, polyCoeffsOrZero(poly, d)...)
+		}
+	}
+	return writeParamsBinaryChunk(w, coeffs, compress)
+}
+
+func readMatrixBinary(r io.Reader, ringQ *ring.Ring, d int, compress bool) (*matrix.Matrix, error) {
+	var rows, cols int32
+	if err := binary.Read(r, binary.LittleEndian, &rows); err != nil {
+		return nil, fmt.Errorf("psi: read matrix rows: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &cols); err != nil {
+		return nil, fmt.Errorf("psi: read matrix cols: %w", err)
+	}
+	if rows <= 0 {
+		return nil, nil
+	}
+	if cols < 0 {
+		return nil, fmt.Errorf("psi: matrix cols %d is negative", cols)
+	}
+	count := int(rows) * int(cols) * d
+	if count < 0 || count > maxFramePayload/8 {
+		return nil, fmt.Errorf("psi: matrix shape %dx%d (d=%d) claims %d coefficients, exceeds limit %d", rows, cols, d, count, maxFramePayload/8)
+	}
+
+	coeffs, err := readParamsBinaryChunk(r, count, compress)
+	if err != nil {
+		return nil, fmt.Errorf("psi: read matrix chunk: %w", err)
+	}
+
+	mat := matrix.NewMatrix(int(rows), int(cols), ringQ)
+	idx := 0
+	for i := 0; i < int(rows); i++ {
+		for j := 0; j < int(cols); j++ {
+			copy(mat.Elements[i][j].Coeffs[0], coeffs[idx:idx+d])
+			idx += d
+		}
+	}
+	return mat, nil
+}
+
+// polyCoeffsOrZero returns poly's first d coefficients, or a zero-filled
+// slice of length d if poly is nil/empty.
+func polyCoeffsOrZero(poly *ring.Poly, d int) []uint64 {
+	if poly != nil && poly.Coeffs != nil && len(poly.Coeffs) > 0 && len(poly.Coeffs[0]) >= d {
+		return poly.Coeffs[0][:d]
+	}
+	return make([]uint64, d)
+}
+
+// writeParamsBinaryString writes s as a uint16 length prefix followed by its
+// UTF-8 bytes, for trailing metadata (HashScheme) too small to need
+// writeParamsBinaryChunk's uint32/compression machinery.
+func writeParamsBinaryString(w io.Writer, s string) error {
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(s))); err != nil {
+		return fmt.Errorf("psi: write string length: %w", err)
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+// readParamsBinaryString reads a string written by writeParamsBinaryString.
+// It returns io.EOF, unmodified, when r is exhausted before the length
+// prefix can be read, so a caller reading an older stream with no trailer
+// can treat that as "no value" instead of a corrupt stream.
+func readParamsBinaryString(r io.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		if err == io.EOF {
+			return "", io.EOF
+		}
+		return "", fmt.Errorf("psi: read string length: %w", err)
+	}
+	if length == 0 {
+		return "", nil
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", fmt.Errorf("psi: read string: %w", err)
+	}
+	return string(buf), nil
+}
+
+// MarshalBinary encodes s in the binary wire format SerializeParametersBinary
+// writes (uncompressed), with s.HashScheme appended as a trailer, so a
+// SerializableParams already in memory - e.g. round-tripped through JSON -
+// satisfies encoding.BinaryMarshaler instead of only encoding/json.Marshaler.
+func (s *SerializableParams) MarshalBinary() ([]byte, error) {
+	pp, msg, le, err := DeserializeParameters(s)
+	if err != nil {
+		return nil, fmt.Errorf("psi: marshal binary: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := SerializeParametersBinary(&buf, pp, msg, le, false); err != nil {
+		return nil, fmt.Errorf("psi: marshal binary: %w", err)
+	}
+	if err := writeParamsBinaryString(&buf, s.HashScheme); err != nil {
+		return nil, fmt.Errorf("psi: marshal binary: write hash scheme: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes data written by MarshalBinary (or bare
+// SerializeParametersBinary output, which has no HashScheme trailer) into s,
+// satisfying encoding.BinaryUnmarshaler.
+func (s *SerializableParams) UnmarshalBinary(data []byte) error {
+	params, err := DeserializeParametersFromReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("psi: unmarshal binary: %w", err)
+	}
+	*s = *params
+	return nil
+}
+
+// DeserializeParametersFromReader reads the binary wire format directly from
+// r - SerializeParametersBinary's chunks plus the HashScheme trailer
+// MarshalBinary appends - and returns the reconstructed SerializableParams,
+// so a client can consume a /api/params.bin response without holding the
+// full encoded body or an intermediate pp/msg/le triple in memory at once.
+func DeserializeParametersFromReader(r io.Reader) (*SerializableParams, error) {
+	pp, msg, le, err := DeserializeParametersBinary(r)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := readParamsBinaryString(r)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("psi: read hash scheme: %w", err)
+	}
+	return SerializeParametersWithScheme(pp, msg, le, scheme), nil
+}
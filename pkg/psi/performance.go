@@ -36,6 +36,12 @@ type PerformanceMonitor struct {
 	IntersectionTime time.Duration
 	TotalOperations  int
 	NumWorkers       int
+
+	// Noise and NoiseQ, if set, make GetMetrics/Observe include Noise's
+	// NoiseBudgetReport (keyed by ciphertext index) and exhausted-event
+	// count alongside the timing breakdown.
+	Noise  *NoiseBudgetTracker
+	NoiseQ uint64
 }
 
 // NewPerformanceMonitor creates a new performance monitor initialized with
@@ -159,12 +165,12 @@ func (pm *PerformanceMonitor) GetThroughput() float64 {
 //
 // Returns:
 //   - map[string]interface{}: Comprehensive metrics including:
-//     - total_time_seconds, total_time_formatted
-//     - key_gen_time_seconds, key_gen_time_formatted, key_gen_percent
-//     - hashing_time_seconds, hashing_time_formatted, hashing_percent
-//     - witness_time_seconds, witness_time_formatted, witness_percent
-//     - intersection_time_seconds, intersection_time_formatted, intersection_percent
-//     - num_workers, total_operations, throughput_ops_per_sec
+//   - total_time_seconds, total_time_formatted
+//   - key_gen_time_seconds, key_gen_time_formatted, key_gen_percent
+//   - hashing_time_seconds, hashing_time_formatted, hashing_percent
+//   - witness_time_seconds, witness_time_formatted, witness_percent
+//   - intersection_time_seconds, intersection_time_formatted, intersection_percent
+//   - num_workers, total_operations, throughput_ops_per_sec
 //
 // Example:
 //
@@ -196,19 +202,54 @@ func (pm *PerformanceMonitor) GetMetrics() map[string]interface{} {
 		metrics["intersection_percent"] = (pm.IntersectionTime.Seconds() / totalTime.Seconds()) * 100
 	}
 
+	if pm.Noise != nil {
+		metrics["noise_exhausted_events"] = pm.Noise.ExhaustedEvents()
+		metrics["noise_budget_report"] = pm.Noise.NoiseBudgetReport(pm.NoiseQ)
+	}
+
 	return metrics
 }
 
+// Observe publishes the monitor's current readings into m's Prometheus
+// collectors: the per-phase durations, TotalOperations, and the goroutine
+// count from GetMemoryUsage. Call it whenever PrintReport/GetMetrics would
+// otherwise be the only way to see these numbers, e.g. right before
+// returning from ServerInitialize or DetectIntersectionWithContext.
+//
+// Example:
+//
+//	monitor.Observe(psi.DefaultMetrics())
+func (pm *PerformanceMonitor) Observe(m *Metrics) {
+	m.KeyGenDuration.Observe(pm.KeyGenTime.Seconds())
+	m.HashingDuration.Observe(pm.HashingTime.Seconds())
+	m.WitnessDuration.Observe(pm.WitnessTime.Seconds())
+	m.IntersectionPhase.Observe(pm.IntersectionTime.Seconds())
+	if pm.TotalOperations > 0 {
+		m.TotalOperations.Add(float64(pm.TotalOperations))
+	}
+
+	mem := pm.GetMemoryUsage()
+	m.Goroutines.Set(float64(mem["goroutines"].(int)))
+	m.MemoryUsageMB.Set(mem["alloc_mb"].(float64))
+
+	if pm.Noise != nil {
+		m.NoiseExhaustedEvents.Add(float64(pm.Noise.ExhaustedEvents()))
+		if minBits, ok := pm.Noise.MinRemainingBits(pm.NoiseQ); ok {
+			m.NoiseRemainingBitsMin.Set(minBits)
+		}
+	}
+}
+
 // GetMemoryUsage returns current memory statistics from the Go runtime.
 // Useful for monitoring resource consumption during PSI operations.
 //
 // Returns:
 //   - map[string]interface{}: Memory metrics including:
-//     - alloc_mb: Currently allocated heap memory in MB
-//     - total_alloc_mb: Cumulative allocated memory in MB
-//     - sys_mb: Total memory obtained from OS in MB
-//     - num_gc: Number of completed GC cycles
-//     - goroutines: Current number of goroutines
+//   - alloc_mb: Currently allocated heap memory in MB
+//   - total_alloc_mb: Cumulative allocated memory in MB
+//   - sys_mb: Total memory obtained from OS in MB
+//   - num_gc: Number of completed GC cycles
+//   - goroutines: Current number of goroutines
 //
 // Example:
 //
@@ -1,19 +1,17 @@
 package psi
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"log"
 	"runtime"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/SanthoshCheemala/LE-PSI/internal/storage"
+	"github.com/SanthoshCheemala/LE-PSI/internal/storage/migrations"
 	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
 	"github.com/SanthoshCheemala/LE-PSI/pkg/matrix"
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/tuneinsight/lattigo/v3/ring"
 )
 
@@ -30,7 +28,19 @@ import (
 //   - WitnessVectors2: Second set of witness vectors for tree navigation
 //   - TreeIndices: Mapped indices of server's dataset in the witness tree
 //   - OriginalHashes: Original hash values of server's dataset elements
-//   - DBPath: Path to the witness tree database file
+//   - Backend: Storage backend the witness tree was built against
+//   - BucketMap: TreeIndices grouped by value, so DetectIntersectionWithContextBucketed
+//     only decrypts against the server indices sharing a client query's bucket
+//   - Version: bumped by AddEntries/RemoveEntries so a caller holding a
+//     cached PublicParams (e.g. from /api/status) can tell it's stale
+//   - WorkerPolicy: the (optionally Calibrate'd) policy ServerInitialize
+//     sized its own worker pools with; DetectIntersectionWithContextFiltered
+//     and DetectIntersectionWithContextBucketed reuse it instead of each
+//     recomputing AutoDetectPolicy() per call
+//   - Cuckoo: non-nil when WithCuckooHashing was passed, the layout
+//     TreeIndices/BucketMap were built from instead of a plain
+//     ReduceToTreeIndex pass; GetCuckooSeeds exposes the seeds a client
+//     needs to query against it
 //
 // The context should be cleaned up after use by calling Cleanup() method
 // to properly close database connections and free resources.
@@ -43,7 +53,38 @@ type ServerInitContext struct {
 	WitnessVectors2 [][]*matrix.Vector
 	TreeIndices     []uint64
 	OriginalHashes  []uint64
-	DBPath          string
+	Backend         storage.Backend
+	Bloom           *BloomPrefilter
+	Noise           *NoiseBudget
+	NoiseTracker    *NoiseBudgetTracker
+	BucketMap       map[uint64][]int
+	Version         uint64
+	WorkerPolicy    WorkerPolicy
+	Cuckoo          *CuckooLayout
+	// Decoder is consulted by DetectIntersectionWithContextConfidence; nil
+	// means ThresholdDecoder{} (CorrectnessCheck's historical behavior). Set
+	// via WithDecoder.
+	Decoder Decoder
+}
+
+// Close releases the storage backend ServerInitialize opened for ctx.
+func (ctx *ServerInitContext) Close() error {
+	if ctx.Backend == nil {
+		return nil
+	}
+	return ctx.Backend.Close()
+}
+
+// bloomTargetFPR is the false-positive rate BuildBloomPrefilter is sized for
+// when ServerInitialize builds the pre-filtering layer. 1% keeps the bit
+// array small while still skipping the vast majority of non-matching client
+// queries in the common sparse-intersection case.
+const bloomTargetFPR = 0.01
+
+// bloomPath derives the sidecar Bloom filter path from the tree DB path so
+// SaveBloomPrefilter/LoadBloomPrefilter can find it without extra plumbing.
+func bloomPath(treepath string) string {
+	return treepath + ".bloom"
 }
 
 // GetPublicParameters extracts the public parameters from the server context.
@@ -65,6 +106,17 @@ func GetPublicParameters(ctx *ServerInitContext) (*matrix.Vector, *ring.Poly, *L
 	return ctx.PublicParams, ctx.Message, ctx.LEParams
 }
 
+// GetCuckooSeeds returns the CuckooSeeds a cuckoo-hashed ServerInitContext
+// was built with, so the client can compute the same k candidate leaves via
+// CuckooSeeds.Candidates (see ClientEncryptCuckoo). The second return is
+// false if ctx wasn't built with WithCuckooHashing.
+func GetCuckooSeeds(ctx *ServerInitContext) (CuckooSeeds, bool) {
+	if ctx.Cuckoo == nil {
+		return CuckooSeeds{}, false
+	}
+	return ctx.Cuckoo.Seeds, true
+}
+
 // SerializableParams represents PSI public parameters in a JSON-serializable format.
 // Use SerializeParameters to create and DeserializeParameters to reconstruct.
 type SerializableParams struct {
@@ -80,6 +132,11 @@ type SerializableParams struct {
 	A1NTT  [][][]uint64 `json:"a1ntt"`
 	BNTT   [][][]uint64 `json:"bntt"`
 	GNTT   [][][]uint64 `json:"gntt"`
+	// HashScheme names the utils.Hasher (see utils.Hasher.Scheme) that
+	// produced the OriginalHashes/TreeIndices on the sending side, e.g.
+	// "sha256", "hkdf-sha256", "blake3", or "cuckoo". Empty means "sha256",
+	// matching utils.HashDataPoints' default before HashScheme existed.
+	HashScheme string `json:"hash_scheme,omitempty"`
 }
 
 // SerializeParameters converts public parameters into a serializable format for network transmission.
@@ -139,6 +196,15 @@ func SerializeParameters(pp *matrix.Vector, msg *ring.Poly, le *LE.LE) *Serializ
 	}
 }
 
+// SerializeParametersWithScheme is SerializeParameters with an explicit
+// HashScheme tag, for deployments using a utils.Hasher other than the
+// SHA256Hasher default (see utils.Hasher.Scheme for the expected values).
+func SerializeParametersWithScheme(pp *matrix.Vector, msg *ring.Poly, le *LE.LE, scheme string) *SerializableParams {
+	params := SerializeParameters(pp, msg, le)
+	params.HashScheme = scheme
+	return params
+}
+
 // DeserializeParameters reconstructs public parameters from serialized format.
 // Use this on the client side to receive parameters from the server.
 //
@@ -210,19 +276,71 @@ func DeserializeParameters(params *SerializableParams) (*matrix.Vector, *ring.Po
 	return ppVec, msgPoly, le, nil
 }
 
+// serverInitConfig holds ServerInitialize's optional settings; see
+// ServerInitOption and WithWorkerPolicy.
+type serverInitConfig struct {
+	workerPolicy   *WorkerPolicy
+	cuckooK        int
+	cuckooMaxKicks int
+	decoder        Decoder
+}
+
+// ServerInitOption configures ServerInitialize, following the same
+// functional-option shape as CSVIngestOption (csv_ingest.go) and
+// LEParamOption (parameters.go).
+type ServerInitOption func(*serverInitConfig)
+
+// WithWorkerPolicy overrides ServerInitialize's AutoDetectPolicy() default
+// for sizing its key-generation and witness-generation worker pools, for a
+// caller that already knows its host's constraints (or wants
+// CalculateOptimalWorkersWithPolicy's PSI_WORKERS/PSI_MAX_RAM_GB env
+// overrides bypassed entirely).
+func WithWorkerPolicy(policy WorkerPolicy) ServerInitOption {
+	return func(c *serverInitConfig) {
+		c.workerPolicy = &policy
+	}
+}
+
+// WithCuckooHashing replaces ServerInitialize's default single-hash
+// ReduceToTreeIndex assignment with a k-candidate cuckoo layout (see
+// BuildCuckooLayout), so two distinct server elements practically never
+// collide onto the same witness-tree leaf even well past the birthday bound
+// for len(private_set_X) against 2^layers. maxKicks bounds how many eviction
+// chains an insertion may trigger before the element is given up on and
+// moved to the layout's stash instead.
+func WithCuckooHashing(k, maxKicks int) ServerInitOption {
+	return func(c *serverInitConfig) {
+		c.cuckooK = k
+		c.cuckooMaxKicks = maxKicks
+	}
+}
+
+// WithDecoder sets ctx.Decoder, consulted by
+// DetectIntersectionWithContextConfidence in place of the ThresholdDecoder{}
+// default (CorrectnessCheck's historical 95% threshold). DetectIntersectionWithContext
+// and the streaming IntersectionSession/DetectIntersectionStream paths always
+// decode with CorrectnessCheck directly, regardless of this option, so their
+// pass/fail semantics never change out from under an existing caller.
+func WithDecoder(d Decoder) ServerInitOption {
+	return func(c *serverInitConfig) {
+		c.decoder = d
+	}
+}
+
 // ServerInitialize prepares the server-side PSI context with the server's private dataset.
 // This function must be called before performing any intersection operations.
 //
 // Parameters:
 //   - private_set_X: Server's private dataset (slice of uint64 values)
 //   - Treepath: Path to the database file for storing the witness tree structure
+//   - opts: Optional settings; see WithWorkerPolicy
 //
 // Returns:
 //   - *ServerInitContext: Initialized server context containing:
-//     - Lattice encryption parameters (LE)
-//     - Public parameters (PP)
-//     - Message polynomial (Msg)
-//     - Witness tree for efficient lookup
+//   - Lattice encryption parameters (LE)
+//   - Public parameters (PP)
+//   - Message polynomial (Msg)
+//   - Witness tree for efficient lookup
 //   - error: Returns error if parameter setup fails or tree creation fails
 //
 // Example:
@@ -232,40 +350,118 @@ func DeserializeParameters(params *SerializableParams) (*matrix.Vector, *ring.Po
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
-//	defer ctx.Cleanup()
-func ServerInitialize(private_set_X []uint64, Treepath string) (*ServerInitContext, error) {
+//	defer ctx.Close()
+func ServerInitialize(private_set_X []uint64, Treepath string, opts ...ServerInitOption) (*ServerInitContext, error) {
 	monitor := NewPerformanceMonitor()
 
+	cfg := serverInitConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	X_size := len(private_set_X)
 	if X_size == 0 {
 		return nil, errors.New("server set is empty")
 	}
 
-	leParams, err := SetupLEParameters(len(private_set_X))
+	leParams, resolvedParams, err := SetupLEParameters(len(private_set_X))
 	if err != nil {
 		return nil, fmt.Errorf("SetupLEParameters: %w", err)
 	}
+	log.Printf("LE parameters: layers=%d load_factor=%.6f collision_probability=%.6e",
+		resolvedParams.Layers, resolvedParams.LoadFactor, resolvedParams.CollisionProbability)
+
+	workerPolicy := cfg.workerPolicy
+	if workerPolicy == nil {
+		p := AutoDetectPolicy().Calibrate(leParams)
+		workerPolicy = &p
+	}
 
-	db, err := sql.Open("sqlite3", Treepath)
+	// Treepath is a DSN ("sqlite://...", "postgres://...") or, for backward
+	// compatibility, a bare SQLite filesystem path.
+	backend, err := storage.OpenBackend(Treepath)
 	if err != nil {
-		return nil, fmt.Errorf("open tree db: %w", err)
+		return nil, fmt.Errorf("open tree backend: %w", err)
 	}
-	defer db.Close()
 
-	if err := storage.InitializeTreeDB(db, leParams.Layers); err != nil {
-		log.Printf("warning: InitializeTreeDB returned: %v\n", err)
+	// LE.Upd/LE.ReadFromDB/LE.WitGen below take a raw *sql.DB; pkg/LE lives
+	// outside this repository snapshot and can't be migrated to the
+	// Backend interface from here, so Raw() bridges the two.
+	db := backend.Raw()
+
+	if err := migrations.Up(db, backend.Dialect()); err != nil {
+		return nil, fmt.Errorf("apply schema migrations: %w", err)
 	}
 
+	recordedParams, haveRecordedParams, err := migrations.LoadLEParams(db)
+	if err != nil {
+		return nil, fmt.Errorf("load recorded LE parameters: %w", err)
+	}
+	if haveRecordedParams {
+		if recordedParams.Mismatch(leParams.Q, leParams.D, leParams.Layers, leParams.M, leParams.M2) {
+			return nil, fmt.Errorf(
+				"tree at %s was built with different LE parameters (q=%d d=%d layers=%d m=%d m2=%d); refusing to start against incompatible parameters",
+				Treepath, recordedParams.Q, recordedParams.D, recordedParams.Layers, recordedParams.M, recordedParams.M2)
+		}
+	} else {
+		rec := migrations.LEParamsRecord{Q: leParams.Q, D: leParams.D, Layers: leParams.Layers, M: leParams.M, M2: leParams.M2}
+		if err := migrations.SaveLEParams(db, rec, backend.Dialect()); err != nil {
+			return nil, fmt.Errorf("record LE parameters: %w", err)
+		}
+	}
+
+	var cuckoo *CuckooLayout
+	if cfg.cuckooK > 0 {
+		recordedSeeds, haveRecordedSeeds, err := migrations.LoadCuckooSeeds(db)
+		if err != nil {
+			return nil, fmt.Errorf("load recorded cuckoo seeds: %w", err)
+		}
+
+		var seeds CuckooSeeds
+		if haveRecordedSeeds {
+			if recordedSeeds.K != cfg.cuckooK {
+				return nil, fmt.Errorf(
+					"tree at %s was built with cuckoo k=%d; refusing to start with k=%d",
+					Treepath, recordedSeeds.K, cfg.cuckooK)
+			}
+			seeds = CuckooSeeds{Salt: recordedSeeds.Salt}
+		} else {
+			seeds, err = NewCuckooSeeds()
+			if err != nil {
+				return nil, fmt.Errorf("generate cuckoo seeds: %w", err)
+			}
+			rec := migrations.CuckooSeedsRecord{Salt: seeds.Salt, K: cfg.cuckooK}
+			if err := migrations.SaveCuckooSeeds(db, rec, backend.Dialect()); err != nil {
+				return nil, fmt.Errorf("record cuckoo seeds: %w", err)
+			}
+		}
+
+		cuckoo, err = BuildCuckooLayoutWithSeeds(private_set_X, leParams.Layers, cfg.cuckooK, cfg.cuckooMaxKicks, seeds)
+		if err != nil {
+			return nil, fmt.Errorf("build cuckoo layout: %w", err)
+		}
+		if len(cuckoo.Stash) > 0 {
+			log.Printf("cuckoo layout: %d/%d elements stashed onto a dedicated fallback leaf, unreachable via a client's bucketed query (k=%d maxKicks=%d)",
+				len(cuckoo.Stash), X_size, cfg.cuckooK, cfg.cuckooMaxKicks)
+		}
+	}
+
+	if err := backend.OpenTreeStore(leParams.Layers); err != nil {
+		log.Printf("warning: OpenTreeStore returned: %v\n", err)
+	}
+
+	DefaultMetrics().TreeDepth.Set(float64(leParams.Layers))
+
 	publicKeys := make([]*matrix.Vector, X_size)
 	privateKeys := make([]*matrix.Vector, X_size)
 	hashedClient := make([]uint64, X_size)
 	keyGenStart := time.Now()
 
-	numWorkers := CalculateOptimalWorkers(X_size)
+	numWorkers := CalculateOptimalWorkersWithPolicy(X_size, *workerPolicy)
 	if numWorkers > X_size {
 		numWorkers = X_size
 	}
-	
+
 	workChan := make(chan int, X_size)
 	var wg sync.WaitGroup
 
@@ -275,7 +471,11 @@ func ServerInitialize(private_set_X []uint64, Treepath string) (*ServerInitConte
 			defer wg.Done()
 			for i := range workChan {
 				publicKeys[i], privateKeys[i] = leParams.KeyGen()
-				hashedClient[i] = ReduceToTreeIndex(private_set_X[i], leParams.Layers)
+				if cuckoo != nil {
+					hashedClient[i] = cuckoo.Leaf[i]
+				} else {
+					hashedClient[i] = ReduceToTreeIndex(private_set_X[i], leParams.Layers)
+				}
 			}
 		}()
 	}
@@ -305,11 +505,11 @@ func ServerInitialize(private_set_X []uint64, Treepath string) (*ServerInitConte
 	witnessStart := time.Now()
 	witnessesVec1 := make([][]*matrix.Vector, X_size)
 	witnessesVec2 := make([][]*matrix.Vector, X_size)
-	
+
 	witnessChan := make(chan int, X_size)
 	var witnessWg sync.WaitGroup
 
-	numWorkers = CalculateOptimalWorkers(X_size)
+	numWorkers = CalculateOptimalWorkersWithPolicy(X_size, *workerPolicy)
 	for w := 0; w < numWorkers; w++ {
 		witnessWg.Add(1)
 		go func() {
@@ -329,6 +529,13 @@ func ServerInitialize(private_set_X []uint64, Treepath string) (*ServerInitConte
 
 	monitor.PrintReport()
 
+	bloom := BuildBloomPrefilter(private_set_X, bloomTargetFPR)
+	if err := SaveBloomPrefilter(bloom, bloomPath(Treepath)); err != nil {
+		log.Printf("warning: could not persist bloom prefilter: %v\n", err)
+	}
+
+	bucketMap := buildBucketMap(hashedClient)
+
 	ctx := &ServerInitContext{
 		PublicParams:    pp,
 		Message:         msg,
@@ -338,12 +545,46 @@ func ServerInitialize(private_set_X []uint64, Treepath string) (*ServerInitConte
 		WitnessVectors2: witnessesVec2,
 		TreeIndices:     hashedClient,
 		OriginalHashes:  private_set_X,
-		DBPath:          Treepath,
+		Backend:         backend,
+		Bloom:           bloom,
+		Noise:           NewNoiseBudget(leParams.Layers),
+		NoiseTracker:    NewNoiseBudgetTracker(defaultNoiseBudgetThreshold),
+		BucketMap:       bucketMap,
+		WorkerPolicy:    *workerPolicy,
+		Cuckoo:          cuckoo,
+		Decoder:         cfg.decoder,
 	}
 
 	return ctx, nil
 }
 
+// buildBucketMap groups treeIndices by value, mapping each witness-tree leaf
+// to the server indices whose element landed on it. A true client/server
+// match always shares a leaf (both sides reduce the same raw hash with
+// ReduceToTreeIndex), so DetectIntersectionWithContextBucketed can restrict
+// its decrypt loop to a client query's bucket without losing any match -
+// the buckets are expected to hold close to loadFactor items each, per
+// SetupLEParameters' sizing.
+func buildBucketMap(treeIndices []uint64) map[uint64][]int {
+	buckets := make(map[uint64][]int, len(treeIndices))
+	for i, idx := range treeIndices {
+		buckets[idx] = append(buckets[idx], i)
+	}
+	return buckets
+}
+
+// allIndices returns [0, n) as a slice, the "no bucket restriction" set of
+// server indices IntersectionSession.Feed falls back to scanning when it
+// has no bucket id to restrict against, so it degrades to exactly the same
+// full scan DetectIntersectionWithContext itself runs.
+func allIndices(n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = i
+	}
+	return out
+}
+
 // DetectIntersectionWithContext computes the intersection between server and client datasets.
 // It decrypts the client's ciphertexts and identifies matching elements.
 //
@@ -363,14 +604,75 @@ func ServerInitialize(private_set_X []uint64, Treepath string) (*ServerInitConte
 //	}
 //	fmt.Printf("Found %d common elements\n", len(intersection))
 func DetectIntersectionWithContext(ctx *ServerInitContext, clientCiphertexts []Cxtx) ([]uint64, error) {
+	monitor := NewPerformanceMonitor()
+	intersectionStart := time.Now()
+
+	in := make(chan Cxtx, len(clientCiphertexts))
+	out := make(chan uint64, len(clientCiphertexts))
+
+	var streamErr error
+	var streamWg sync.WaitGroup
+	streamWg.Add(1)
+	go func() {
+		defer streamWg.Done()
+		defer close(out)
+		streamErr = DetectIntersectionStream(ctx, in, out)
+	}()
+
+	for _, c := range clientCiphertexts {
+		in <- c
+	}
+	close(in)
+
+	var Z []uint64
+	for hash := range out {
+		Z = append(Z, hash)
+	}
+	streamWg.Wait()
+
+	monitor.TrackIntersectionDetection(intersectionStart)
+	monitor.TotalOperations = len(clientCiphertexts) * len(ctx.OriginalHashes)
+	monitor.Noise = ctx.NoiseTracker
+	monitor.NoiseQ = ctx.LEParams.Q
+	monitor.PrintReport()
+	monitor.Observe(DefaultMetrics())
+
+	return Z, streamErr
+}
+
+// DetectIntersectionWithContextFiltered behaves like
+// DetectIntersectionWithContext, but first consults ctx.Bloom (built by
+// ServerInitialize) to skip the decrypt-and-check path for client items that
+// the filter can prove are not in the server's set. clientHashes must be the
+// plaintext hash the client is querying for, in the same order as
+// clientCiphertexts; it never leaves the server process and is only used
+// against the locally-built filter.
+//
+// If ctx.Bloom is nil, or clientHashes does not have one entry per
+// ciphertext, this falls back to the unfiltered full scan so callers that
+// can't supply plaintext hashes keep working unchanged.
+func DetectIntersectionWithContextFiltered(ctx *ServerInitContext, clientCiphertexts []Cxtx, clientHashes []uint64) ([]uint64, int, error) {
+	if ctx.Bloom == nil || len(clientHashes) != len(clientCiphertexts) {
+		Z, err := DetectIntersectionWithContext(ctx, clientCiphertexts)
+		return Z, 0, err
+	}
+
 	runtime.GC()
-	
+
 	monitor := NewPerformanceMonitor()
 	intersectionStart := time.Now()
 
 	X_size := len(ctx.OriginalHashes)
 
-	numWorkers := CalculateOptimalWorkers(X_size)
+	candidates := make([]int, 0, len(clientCiphertexts))
+	for j, h := range clientHashes {
+		if ctx.Bloom.Test(h) {
+			candidates = append(candidates, j)
+		}
+	}
+	skipped := len(clientCiphertexts) - len(candidates)
+
+	numWorkers := CalculateOptimalWorkersWithPolicy(X_size, ctx.WorkerPolicy)
 	if numWorkers < 1 {
 		numWorkers = 1
 	}
@@ -382,27 +684,9 @@ func DetectIntersectionWithContext(ctx *ServerInitContext, clientCiphertexts []C
 	type workItem struct {
 		j, k int
 	}
-	totalWork := len(clientCiphertexts) * X_size
+	totalWork := len(candidates) * X_size
 	workItems := make(chan workItem, totalWork)
 	var detectionWg sync.WaitGroup
-	
-	var processedCount uint64
-	doneChan := make(chan struct{})
-	
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				current := atomic.LoadUint64(&processedCount)
-				percent := float64(current) / float64(totalWork) * 100
-				log.Printf("   ... Progress: %d/%d (%.1f%%)", current, totalWork, percent)
-			case <-doneChan:
-				return
-			}
-		}
-	}()
 
 	for w := 0; w < numWorkers; w++ {
 		detectionWg.Add(1)
@@ -413,9 +697,11 @@ func DetectIntersectionWithContext(ctx *ServerInitContext, clientCiphertexts []C
 					log.Printf("CRITICAL: Worker panic: %v", r)
 				}
 			}()
-			
+
+			metrics := DefaultMetrics()
 			for item := range workItems {
 				j, k := item.j, item.k
+				itemStart := time.Now()
 				msg2 := LE.Dec(ctx.LEParams, ctx.PrivateKeys[k], ctx.WitnessVectors1[k], ctx.WitnessVectors2[k],
 					clientCiphertexts[j].C0, clientCiphertexts[j].C1, clientCiphertexts[j].C, clientCiphertexts[j].D)
 
@@ -424,28 +710,125 @@ func DetectIntersectionWithContext(ctx *ServerInitContext, clientCiphertexts []C
 					if !intersectionMap[k] {
 						Z = append(Z, ctx.OriginalHashes[k])
 						intersectionMap[k] = true
+						metrics.Matches.Inc()
 					}
 					resultMutex.Unlock()
 				}
-				atomic.AddUint64(&processedCount, 1)
+				metrics.DetectionLatency.Observe(time.Since(itemStart).Seconds())
 			}
 		}()
 	}
 
-	for j := range clientCiphertexts {
+	for _, j := range candidates {
 		for k := 0; k < X_size; k++ {
 			workItems <- workItem{j: j, k: k}
 		}
 	}
 	close(workItems)
 	detectionWg.Wait()
-	close(doneChan)
-	
+
 	monitor.TrackIntersectionDetection(intersectionStart)
+	monitor.TotalOperations = totalWork
+	monitor.PrintReport()
+	log.Printf("bloom prefilter skipped %d/%d client queries", skipped, len(clientCiphertexts))
+
+	return Z, skipped, nil
+}
 
+// DetectIntersectionWithContextBucketed behaves like
+// DetectIntersectionWithContext, but restricts each client ciphertext's
+// decrypt loop to the server indices in ctx.BucketMap[bucketIDs[j]] instead
+// of every server index, turning the O(|clientCiphertexts| * |X|) scan into
+// O(|clientCiphertexts| * avg_bucket_size). bucketIDs must hold one entry
+// per clientCiphertexts[j], computed by ClientBucketIDs against the same
+// witness-tree layer count the server's LEParams uses - a true match always
+// lands in the same bucket on both sides, since both reduce the same raw
+// hash with ReduceToTreeIndex, so no match is missed by only checking it.
+//
+// If ctx.BucketMap is nil, or bucketIDs does not have one entry per
+// ciphertext, this falls back to the unfiltered full scan so a client that
+// omits bucket ids keeps working unchanged.
+func DetectIntersectionWithContextBucketed(ctx *ServerInitContext, clientCiphertexts []Cxtx, bucketIDs []uint64) ([]uint64, error) {
+	if ctx.BucketMap == nil || len(bucketIDs) != len(clientCiphertexts) {
+		return DetectIntersectionWithContext(ctx, clientCiphertexts)
+	}
+
+	runtime.GC()
+
+	monitor := NewPerformanceMonitor()
+	intersectionStart := time.Now()
+
+	X_size := len(ctx.OriginalHashes)
+	numWorkers := CalculateOptimalWorkersWithPolicy(X_size, ctx.WorkerPolicy)
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	var Z []uint64
+	intersectionMap := make(map[int]bool)
+	var resultMutex sync.Mutex
+
+	type workItem struct {
+		j, k int
+	}
+
+	totalWork := 0
+	for _, id := range bucketIDs {
+		totalWork += len(ctx.BucketMap[id])
+	}
+	workItems := make(chan workItem, totalWork)
+	var detectionWg sync.WaitGroup
+
+	for w := 0; w < numWorkers; w++ {
+		detectionWg.Add(1)
+		go func() {
+			defer detectionWg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("CRITICAL: Worker panic: %v", r)
+				}
+			}()
+
+			metrics := DefaultMetrics()
+			for item := range workItems {
+				j, k := item.j, item.k
+				itemStart := time.Now()
+				msg2 := LE.Dec(ctx.LEParams, ctx.PrivateKeys[k], ctx.WitnessVectors1[k], ctx.WitnessVectors2[k],
+					clientCiphertexts[j].C0, clientCiphertexts[j].C1, clientCiphertexts[j].C, clientCiphertexts[j].D)
+
+				if CorrectnessCheck(msg2, ctx.Message, ctx.LEParams) {
+					resultMutex.Lock()
+					if !intersectionMap[k] {
+						Z = append(Z, ctx.OriginalHashes[k])
+						intersectionMap[k] = true
+						metrics.Matches.Inc()
+					}
+					resultMutex.Unlock()
+				}
+				metrics.DetectionLatency.Observe(time.Since(itemStart).Seconds())
+			}
+		}()
+	}
+
+	for j, id := range bucketIDs {
+		for _, k := range ctx.BucketMap[id] {
+			workItems <- workItem{j: j, k: k}
+		}
+	}
+	close(workItems)
+	detectionWg.Wait()
+
+	monitor.TrackIntersectionDetection(intersectionStart)
 	monitor.TotalOperations = totalWork
 	monitor.PrintReport()
 
+	avgBucketSize := float64(0)
+	if len(bucketIDs) > 0 {
+		avgBucketSize = float64(totalWork) / float64(len(bucketIDs))
+	}
+	log.Printf("bucket prefilter: %d decrypt ops for %d client queries (avg bucket size %.2f vs %d full scan)",
+		totalWork, len(clientCiphertexts), avgBucketSize, X_size)
+
 	return Z, nil
 }
 
@@ -0,0 +1,227 @@
+package psi
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/matrix"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// ClientSession holds one PRNG and GaussianSampler pair, reused across
+// repeated Encrypt calls instead of clientEncryptAtIndices' per-batch setup,
+// so a caller encrypting a large private_set_Y one item (or one streamed
+// chunk) at a time doesn't pay PRNG/sampler construction cost on every
+// call. Obtain one via NewClientSession.
+type ClientSession struct {
+	pp  *matrix.Vector
+	msg *ring.Poly
+	le  *LE.LE
+
+	mu      sync.Mutex
+	prng    utils.PRNG
+	sampler *ring.GaussianSampler
+}
+
+// NewClientSession opens a ClientSession against a server's public
+// parameters (see GetPublicParameters), for streaming encryption via
+// Encrypt/EncryptBatch instead of ClientEncrypt's all-at-once []Cxtx - the
+// client-side half of the session API StartIntersection provides on the
+// server.
+func NewClientSession(pp *matrix.Vector, msg *ring.Poly, le *LE.LE) (*ClientSession, error) {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		return nil, fmt.Errorf("psi: new client session PRNG: %w", err)
+	}
+	return &ClientSession{
+		pp:      pp,
+		msg:     msg,
+		le:      le,
+		prng:    prng,
+		sampler: ring.NewGaussianSampler(prng, le.R, le.Sigma, le.Bound),
+	}, nil
+}
+
+// Encrypt encrypts one item against ReduceToTreeIndex(item, le.Layers),
+// reusing s's PRNG/sampler instead of constructing fresh ones the way
+// clientEncryptAtIndices' worker pool does per batch. Safe for concurrent
+// use: encryption is serialized through an internal mutex, since a
+// ring.GaussianSampler isn't itself safe to share across goroutines -
+// EncryptBatch instead gives each of its workers its own ClientSession so
+// they don't contend on this lock.
+func (s *ClientSession) Encrypt(item uint64) Cxtx {
+	le := s.le
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	treeIndex := ReduceToTreeIndex(item, le.Layers)
+
+	r := make([]*matrix.Vector, le.Layers+1)
+	for j := 0; j < le.Layers+1; j++ {
+		r[j] = matrix.NewRandomVec(le.N, le.R, s.prng).NTT(le.R)
+	}
+
+	e := s.sampler.ReadNew()
+	e0 := make([]*matrix.Vector, le.Layers+1)
+	e1 := make([]*matrix.Vector, le.Layers+1)
+	for j := 0; j < le.Layers+1; j++ {
+		if j == le.Layers {
+			e0[j] = matrix.NewNoiseVec(le.M2, le.R, s.prng, le.Sigma, le.Bound).NTT(le.R)
+		} else {
+			e0[j] = matrix.NewNoiseVec(le.M, le.R, s.prng, le.Sigma, le.Bound).NTT(le.R)
+		}
+		e1[j] = matrix.NewNoiseVec(le.M, le.R, s.prng, le.Sigma, le.Bound).NTT(le.R)
+	}
+
+	c0, c1, cvec, dpoly := LE.Enc(le, s.pp, treeIndex, s.msg, r, e0, e1, e)
+	return Cxtx{C0: c0, C1: c1, C: cvec, D: dpoly}
+}
+
+// EncryptBatch encrypts items across a pool of goroutines - each with its
+// own ClientSession, so they don't contend on s.Encrypt's mutex - and sends
+// each Cxtx to out as soon as it's ready rather than returning a
+// materialized slice the way ClientEncrypt does. Closes out once every item
+// has been sent, so a caller can range over out until it drains.
+func (s *ClientSession) EncryptBatch(items []uint64, out chan<- Cxtx) {
+	defer close(out)
+
+	numWorkers := runtime.NumCPU()
+	if numWorkers > len(items) {
+		numWorkers = len(items)
+	}
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	workChan := make(chan uint64, len(items))
+	var wg sync.WaitGroup
+
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			prng, _ := utils.NewPRNG()
+			worker := &ClientSession{
+				pp:      s.pp,
+				msg:     s.msg,
+				le:      s.le,
+				prng:    prng,
+				sampler: ring.NewGaussianSampler(prng, s.le.R, s.le.Sigma, s.le.Bound),
+			}
+
+			for item := range workChan {
+				out <- worker.Encrypt(item)
+			}
+		}()
+	}
+
+	for _, item := range items {
+		workChan <- item
+	}
+	close(workChan)
+	wg.Wait()
+}
+
+// Stats summarizes an IntersectionSession's lifetime, returned by Close.
+type Stats struct {
+	Fed     int           // number of ciphertexts passed to Feed
+	Matched int           // number of distinct server elements matched
+	Elapsed time.Duration // wall-clock time between StartIntersection and Close
+}
+
+// IntersectionSession runs one ciphertext at a time through the same
+// decrypt-and-check loop DetectIntersectionWithContext batches up front, so
+// a caller can pipeline network I/O (reading ciphertexts off a stream, e.g.
+// ClientSession.EncryptBatch's out channel on the wire) with the server's
+// decrypt work, and never needs more than one Cxtx in memory regardless of
+// how large the client set is. Obtain one via ServerInitContext.StartIntersection.
+type IntersectionSession struct {
+	ctx     *ServerInitContext
+	started time.Time
+
+	fed        int
+	matchedSet map[int]bool
+	matches    []uint64
+}
+
+// StartIntersection begins a streaming intersection session against ctx.
+// Unlike DetectIntersectionWithContext, which needs the full client
+// ciphertext slice up front, a session's Feed method accepts ciphertexts
+// one at a time as they arrive.
+func (ctx *ServerInitContext) StartIntersection() *IntersectionSession {
+	return &IntersectionSession{
+		ctx:        ctx,
+		started:    time.Now(),
+		matchedSet: make(map[int]bool),
+	}
+}
+
+// Feed decrypts c against every server element in turn and reports whether
+// it matched. When matched is true, plaintext is the OriginalHashes value
+// of the server element c matched; it's zero otherwise. The first Cxtx to
+// match a given server element reports the hit; later Cxtx matching the
+// same element report matched=true again (so a caller sees every hit) but
+// don't grow the Close summary's intersection slice a second time.
+//
+// Feed always scans every server element, the same way
+// DetectIntersectionWithContext does; it has no bucket id to restrict
+// against c. A caller that has ctx.BucketMap (built automatically whenever
+// the server was built with bucketing or WithCuckooHashing) should use
+// FeedBucketed instead to get DetectIntersectionWithContextBucketed's
+// O(avg_bucket_size) scan per Feed call rather than this O(|X|) one.
+func (s *IntersectionSession) Feed(c Cxtx) (matched bool, plaintext uint64, err error) {
+	s.fed++
+	return s.feedOver(c, allIndices(len(s.ctx.OriginalHashes)))
+}
+
+// FeedBucketed is Feed restricted to ctx.BucketMap[bucketID], the streaming
+// counterpart to DetectIntersectionWithContextBucketed: a caller pipelining
+// ciphertexts through Feed one at a time can still get the bucket/cuckoo
+// placement ServerInitialize already built instead of silently falling back
+// to a full per-item scan. Falls back to Feed's unrestricted scan if
+// ctx.BucketMap is nil, so a session against a server built without
+// bucketing still behaves correctly.
+func (s *IntersectionSession) FeedBucketed(c Cxtx, bucketID uint64) (matched bool, plaintext uint64, err error) {
+	s.fed++
+	ctx := s.ctx
+	if ctx.BucketMap == nil {
+		return s.feedOver(c, allIndices(len(ctx.OriginalHashes)))
+	}
+	return s.feedOver(c, ctx.BucketMap[bucketID])
+}
+
+// feedOver is Feed/FeedBucketed's shared decrypt-and-check core, restricted
+// to the given server indices.
+func (s *IntersectionSession) feedOver(c Cxtx, indices []int) (matched bool, plaintext uint64, err error) {
+	ctx := s.ctx
+	for _, k := range indices {
+		msg2 := LE.Dec(ctx.LEParams, ctx.PrivateKeys[k], ctx.WitnessVectors1[k], ctx.WitnessVectors2[k],
+			c.C0, c.C1, c.C, c.D)
+		if !CorrectnessCheck(msg2, ctx.Message, ctx.LEParams) {
+			continue
+		}
+
+		if !s.matchedSet[k] {
+			s.matchedSet[k] = true
+			s.matches = append(s.matches, ctx.OriginalHashes[k])
+		}
+		return true, ctx.OriginalHashes[k], nil
+	}
+
+	return false, 0, nil
+}
+
+// Close ends s and returns the intersection accumulated across every Feed
+// call, plus a Stats summary.
+func (s *IntersectionSession) Close() ([]uint64, Stats) {
+	return s.matches, Stats{
+		Fed:     s.fed,
+		Matched: len(s.matches),
+		Elapsed: time.Since(s.started),
+	}
+}
@@ -0,0 +1,182 @@
+package psi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/Shopify/sarama"
+	"github.com/tuneinsight/lattigo/v3/ring"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/matrix"
+	"github.com/SanthoshCheemala/LE-PSI/utils"
+)
+
+// defaultStreamingClientBatchSize is used when StreamingClientConfig.BatchSize
+// is left at its zero value.
+const defaultStreamingClientBatchSize = 100
+
+// FieldMapping extracts the PSIKey utils.HashDataPoints should hash from one
+// raw Kafka message value - the streaming-ingestion equivalent of a
+// Prometheus relabel rule. Callers supply whatever field-selection logic
+// their topic's message schema needs (e.g. pulling a customer ID and DOB
+// out of a JSON transaction payload and concatenating them) instead of
+// StreamingClient assuming a fixed schema. ok is false to skip a message
+// that doesn't map to a PSIKey.
+type FieldMapping func(message []byte) (psiKey string, ok bool)
+
+// StreamingClientConfig configures a StreamingClient's Kafka consumption.
+type StreamingClientConfig struct {
+	Brokers []string
+	// Topics lists exact topic names to consume. TopicPattern, if set, is
+	// additionally matched against the cluster's live topic list (e.g.
+	// "^sanctions.*"), so newly created topics matching the pattern are
+	// picked up without a config change.
+	Topics       []string
+	TopicPattern string
+	GroupID      string
+	// BatchSize is how many mapped PSIKeys StreamingClient accumulates
+	// before encrypting and submitting a batch; it defaults to
+	// defaultStreamingClientBatchSize if left at zero.
+	BatchSize int
+	Mapping   FieldMapping
+}
+
+// StreamingClient consumes customer records from Kafka, maps each to a
+// PSIKey via its Mapping, batches them, encrypts each batch with
+// ClientEncrypt, and submits it to a NetServer for intersection - the
+// continuous counterpart to simulation/client's one-shot
+// loadCustomerRecords-then-POST-/api/intersect flow. Kafka offsets for a
+// batch are only marked (and so eventually committed) after the server
+// responds, so a crash mid-batch replays those messages instead of
+// silently dropping them.
+type StreamingClient struct {
+	cfg    StreamingClientConfig
+	pp     *matrix.Vector
+	msg    *ring.Poly
+	le     *LE.LE
+	server *NetClient
+}
+
+// NewStreamingClient builds a StreamingClient that encrypts against
+// pp/msg/le (from NetClient.GetPublicParameters or psi.GetPublicParameters)
+// and submits batches to server.
+func NewStreamingClient(cfg StreamingClientConfig, pp *matrix.Vector, msg *ring.Poly, le *LE.LE, server *NetClient) *StreamingClient {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultStreamingClientBatchSize
+	}
+	return &StreamingClient{cfg: cfg, pp: pp, msg: msg, le: le, server: server}
+}
+
+// Run joins cfg.GroupID as a consumer of cfg.Topics (plus any live topic
+// matching cfg.TopicPattern) and consumes until ctx is done or the consumer
+// group returns an error.
+func (sc *StreamingClient) Run(ctx context.Context) error {
+	config := sarama.NewConfig()
+	config.Version = sarama.V2_8_0_0
+	config.Consumer.Offsets.AutoCommit.Enable = false
+
+	group, err := sarama.NewConsumerGroup(sc.cfg.Brokers, sc.cfg.GroupID, config)
+	if err != nil {
+		return fmt.Errorf("psi: StreamingClient: new consumer group: %w", err)
+	}
+	defer group.Close()
+
+	topics, err := sc.resolveTopics()
+	if err != nil {
+		return fmt.Errorf("psi: StreamingClient: resolve topics: %w", err)
+	}
+
+	handler := &streamingClientHandler{sc: sc}
+	for {
+		if err := group.Consume(ctx, topics, handler); err != nil {
+			return fmt.Errorf("psi: StreamingClient: consume: %w", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// resolveTopics combines cfg.Topics with any cluster topic matching
+// cfg.TopicPattern.
+func (sc *StreamingClient) resolveTopics() ([]string, error) {
+	topics := append([]string{}, sc.cfg.Topics...)
+	if sc.cfg.TopicPattern == "" {
+		return topics, nil
+	}
+
+	pattern, err := regexp.Compile(sc.cfg.TopicPattern)
+	if err != nil {
+		return nil, fmt.Errorf("compile topic pattern %q: %w", sc.cfg.TopicPattern, err)
+	}
+
+	admin, err := sarama.NewClusterAdmin(sc.cfg.Brokers, sarama.NewConfig())
+	if err != nil {
+		return nil, fmt.Errorf("new cluster admin: %w", err)
+	}
+	defer admin.Close()
+
+	all, err := admin.ListTopics()
+	if err != nil {
+		return nil, fmt.Errorf("list topics: %w", err)
+	}
+	for name := range all {
+		if pattern.MatchString(name) {
+			topics = append(topics, name)
+		}
+	}
+	return topics, nil
+}
+
+// streamingClientHandler adapts sarama's consumer-group callback API to
+// StreamingClient's map-batch-encrypt-submit pipeline.
+type streamingClientHandler struct {
+	sc *StreamingClient
+}
+
+func (h *streamingClientHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *streamingClientHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *streamingClientHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	sc := h.sc
+	batchKeys := make([]string, 0, sc.cfg.BatchSize)
+	batchMessages := make([]*sarama.ConsumerMessage, 0, sc.cfg.BatchSize)
+
+	flush := func() error {
+		if len(batchKeys) == 0 {
+			return nil
+		}
+		hashes := utils.HashDataPoints(batchKeys)
+		ciphertexts := ClientEncrypt(hashes, sc.pp, sc.msg, sc.le)
+		if _, err := sc.server.Intersect(ciphertexts); err != nil {
+			return fmt.Errorf("psi: StreamingClient: submit batch of %d: %w", len(batchKeys), err)
+		}
+		for _, m := range batchMessages {
+			sess.MarkMessage(m, "")
+		}
+		batchKeys = batchKeys[:0]
+		batchMessages = batchMessages[:0]
+		return nil
+	}
+
+	for msg := range claim.Messages() {
+		psiKey, ok := sc.cfg.Mapping(msg.Value)
+		if !ok {
+			log.Printf("psi: StreamingClient: message at %s[%d]@%d did not map to a PSIKey, skipping", msg.Topic, msg.Partition, msg.Offset)
+			sess.MarkMessage(msg, "")
+			continue
+		}
+		batchKeys = append(batchKeys, psiKey)
+		batchMessages = append(batchMessages, msg)
+
+		if len(batchKeys) >= sc.cfg.BatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
@@ -0,0 +1,125 @@
+package psi
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+)
+
+// latencyTracker accumulates per-event processing durations so a long-running
+// StreamingServer can report p50/p95/p99 the same way a one-shot
+// DetectIntersectionWithContext call reports total duration via
+// utils.TimingMetrics.
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+func (t *latencyTracker) observe(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples = append(t.samples, d)
+}
+
+// percentile returns the p-th percentile (0 < p < 100) of the observed
+// samples, or 0 if none have been recorded yet.
+func (t *latencyTracker) percentile(p float64) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.samples) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(t.samples))
+	copy(sorted, t.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(p / 100 * float64(len(sorted)))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// LatencyReport is a snapshot of StreamingServer's observed per-event
+// processing latency, in milliseconds, suitable for embedding in
+// utils.TimingMetrics.
+type LatencyReport struct {
+	P50Ms float64
+	P95Ms float64
+	P99Ms float64
+}
+
+// StreamingServer keeps a ServerInitContext resident and checks client
+// ciphertexts against it one event at a time as they arrive over a
+// Transport, instead of the batch ServerInitialize -> Client ->
+// DetectIntersectionWithContext flow. This suits long-running deployments
+// (e.g. continuous contact-discovery) where the client set changes faster
+// than it is practical to restart the server and rebuild the witness tree.
+type StreamingServer struct {
+	ctx       *ServerInitContext
+	transport Transport
+	latency   latencyTracker
+}
+
+// NewStreamingServer creates a StreamingServer that checks ciphertexts
+// received over transport against ctx, the output of ServerInitialize.
+func NewStreamingServer(ctx *ServerInitContext, transport Transport) *StreamingServer {
+	return &StreamingServer{ctx: ctx, transport: transport}
+}
+
+// Run drains transport.Recv in a loop, checking every ciphertext against
+// every element of the resident set, and calls transport.Publish for each
+// confirmed match. It returns when ctx is cancelled or the transport
+// reports ErrTransportClosed; any other Recv/Publish error is returned
+// immediately so the caller can decide whether to restart the stream.
+func (s *StreamingServer) Run(ctx context.Context) error {
+	X_size := len(s.ctx.OriginalHashes)
+	metrics := DefaultMetrics()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		c, err := s.transport.Recv()
+		if err != nil {
+			if err == ErrTransportClosed {
+				return nil
+			}
+			metrics.Errors.Inc()
+			return err
+		}
+
+		start := time.Now()
+		for k := 0; k < X_size; k++ {
+			msg2 := LE.Dec(s.ctx.LEParams, s.ctx.PrivateKeys[k], s.ctx.WitnessVectors1[k], s.ctx.WitnessVectors2[k],
+				c.C0, c.C1, c.C, c.D)
+
+			if CorrectnessCheck(msg2, s.ctx.Message, s.ctx.LEParams) {
+				metrics.Matches.Inc()
+				if err := s.transport.Publish(IntersectionEvent{Hash: s.ctx.OriginalHashes[k]}); err != nil {
+					log.Printf("streaming server: publish failed: %v", err)
+				}
+			}
+		}
+		elapsed := time.Since(start)
+		s.latency.observe(elapsed)
+		metrics.DetectionLatency.Observe(elapsed.Seconds())
+	}
+}
+
+// LatencyReport returns the current p50/p95/p99 per-event processing
+// latency observed since the StreamingServer was created.
+func (s *StreamingServer) LatencyReport() LatencyReport {
+	return LatencyReport{
+		P50Ms: s.latency.percentile(50).Seconds() * 1000,
+		P95Ms: s.latency.percentile(95).Seconds() * 1000,
+		P99Ms: s.latency.percentile(99).Seconds() * 1000,
+	}
+}
@@ -0,0 +1,99 @@
+package testvectors
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/psi"
+)
+
+// TestConformance replays every vector in Dir() against a fresh
+// ServerInitialize/ClientDeterministic/DetectIntersectionWithContext run and
+// checks the result against the vector's pinned expectations. It skips
+// (rather than fails) when the vector directory is empty, since this
+// package ships with no baked-in vectors - see vectors/README.md.
+func TestConformance(t *testing.T) {
+	dir := Dir()
+	if _, err := os.Stat(dir); err != nil {
+		t.Skipf("testvectors: no vector directory at %s: %v", dir, err)
+	}
+
+	vectors, err := LoadVectors(dir)
+	if err != nil {
+		t.Fatalf("LoadVectors: %v", err)
+	}
+	if len(vectors) == 0 {
+		t.Skip("testvectors: no vectors found")
+	}
+
+	for _, v := range vectors {
+		v := v
+		t.Run(v.Name, func(t *testing.T) {
+			treepath := filepath.Join(t.TempDir(), "conformance.db")
+
+			ctx, err := psi.ServerInitialize(v.ServerSet, treepath)
+			if err != nil {
+				t.Fatalf("ServerInitialize: %v", err)
+			}
+			defer ctx.Close()
+
+			pp, msg, le := psi.GetPublicParameters(ctx)
+			ciphertexts := psi.ClientDeterministic(v.ClientSet, pp, msg, le, v.PRNGSeed)
+
+			if v.ExpectedCiphertexts != nil {
+				got, err := json.Marshal(ciphertexts)
+				if err != nil {
+					t.Fatalf("marshal ciphertexts: %v", err)
+				}
+				if !jsonEqual(t, got, v.ExpectedCiphertexts) {
+					t.Errorf("ciphertexts mismatch:\n got=%s\nwant=%s", got, v.ExpectedCiphertexts)
+				}
+			}
+
+			matches, err := psi.DetectIntersectionWithContext(ctx, ciphertexts)
+			if err != nil {
+				t.Fatalf("DetectIntersectionWithContext: %v", err)
+			}
+			if !sameUint64Set(matches, v.ExpectedIntersection) {
+				t.Errorf("intersection mismatch: got=%v want=%v", matches, v.ExpectedIntersection)
+			}
+		})
+	}
+}
+
+// jsonEqual compares two JSON documents structurally rather than
+// byte-for-byte, so whitespace or field-order differences between a
+// hand-authored vector file and encoding/json's own output don't register
+// as a mismatch.
+func jsonEqual(t *testing.T, a, b json.RawMessage) bool {
+	t.Helper()
+	var av, bv interface{}
+	if err := json.Unmarshal(a, &av); err != nil {
+		t.Fatalf("jsonEqual: unmarshal a: %v", err)
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		t.Fatalf("jsonEqual: unmarshal b: %v", err)
+	}
+	an, _ := json.Marshal(av)
+	bn, _ := json.Marshal(bv)
+	return string(an) == string(bn)
+}
+
+func sameUint64Set(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := append([]uint64(nil), a...)
+	bs := append([]uint64(nil), b...)
+	sort.Slice(as, func(i, j int) bool { return as[i] < as[j] })
+	sort.Slice(bs, func(i, j int) bool { return bs[i] < bs[j] })
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
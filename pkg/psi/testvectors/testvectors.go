@@ -0,0 +1,88 @@
+// Package testvectors implements a conformance-test corpus for
+// ClientEncrypt and the server intersection routine, modeled on the
+// filecoin-project/test-vectors approach Filecoin/lotus uses: each vector
+// pins everything a PSI run depends on for reproducibility (a parameter
+// seed, the server/client datasets, and a PRNG seed for ClientDeterministic)
+// alongside the output it must produce, so a refactor - or an independent
+// implementation - can be checked byte-for-byte against a shared ground
+// truth instead of only against itself.
+//
+// CI can point Dir at a sibling test-vectors repository instead of the
+// vectors/ directory checked into this package by setting LEPSI_VECTORS_DIR
+// after checking out LEPSI_VECTORS_BRANCH of that repository; this package
+// only reads the resulting directory and has no knowledge of git itself.
+package testvectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Vector is one conformance test case: a deterministic PSI run plus its
+// expected output.
+type Vector struct {
+	Name string `json:"name"`
+
+	// ParamsSeed selects the LE.LE parameter set this vector runs under -
+	// interpretation (e.g. an index into secureParameterSets, or a target
+	// FPR for AutoTune) is left to the harness constructing the run, since
+	// pkg/psi's parameter selection is not itself seedable.
+	ParamsSeed []byte `json:"params_seed"`
+
+	// ServerSet and ClientSet are the raw (pre-hash) uint64 datasets
+	// ServerInitialize/ClientDeterministic are driven with.
+	ServerSet []uint64 `json:"server_set"`
+	ClientSet []uint64 `json:"client_set"`
+
+	// PRNGSeed drives psi.ClientDeterministic's lattigo
+	// utils.NewKeyedPRNG, so ClientSet's ciphertexts are bit-for-bit
+	// reproducible.
+	PRNGSeed []byte `json:"prng_seed"`
+
+	// ExpectedCiphertexts is the JSON encoding ClientDeterministic's []Cxtx
+	// must byte-for-byte match when marshaled with encoding/json, the same
+	// way simulation/server's IntersectionRequest already does.
+	ExpectedCiphertexts json.RawMessage `json:"expected_ciphertexts"`
+
+	// ExpectedIntersection is the set of hash values ServerSet and
+	// ClientSet are expected to share.
+	ExpectedIntersection []uint64 `json:"expected_intersection"`
+}
+
+// Dir resolves the vector directory a conformance test should load: the
+// LEPSI_VECTORS_DIR environment variable if set, otherwise the vectors/
+// directory checked into this package.
+func Dir() string {
+	if d := os.Getenv("LEPSI_VECTORS_DIR"); d != "" {
+		return d
+	}
+	return "vectors"
+}
+
+// LoadVectors reads every *.json file in dir as a Vector.
+func LoadVectors(dir string) ([]Vector, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("testvectors: read %s: %w", dir, err)
+	}
+
+	var vectors []Vector
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("testvectors: read %s: %w", path, err)
+		}
+		var v Vector
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("testvectors: parse %s: %w", path, err)
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
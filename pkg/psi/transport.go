@@ -0,0 +1,79 @@
+package psi
+
+import "errors"
+
+// ErrTransportClosed is returned by Transport.Recv once the underlying
+// stream has been drained and will not produce any more ciphertexts.
+var ErrTransportClosed = errors.New("psi: transport closed")
+
+// IntersectionEvent is emitted by StreamingServer.Run whenever a client
+// ciphertext received over a Transport turns out to match an element of the
+// server's set. Hash is the server-side plaintext hash (ctx.OriginalHashes
+// element), the same value DetectIntersectionWithContext would append to Z.
+type IntersectionEvent struct {
+	Hash uint64
+}
+
+// Transport decouples StreamingServer from any one messaging system. Recv
+// supplies the next client ciphertext to check against the resident tree;
+// Publish reports a confirmed intersection hit. Implementations are expected
+// to be safe for a single StreamingServer.Run goroutine to drive, but do not
+// need to support concurrent callers of Recv/Publish from multiple
+// goroutines unless documented otherwise.
+type Transport interface {
+	Recv() (Cxtx, error)
+	Publish(hit IntersectionEvent) error
+}
+
+// InprocTransport is an in-memory Transport backed by Go channels, useful
+// for tests and for the benchmark harness's --transport=inproc mode where a
+// real message broker would be overkill.
+type InprocTransport struct {
+	in   chan Cxtx
+	out  chan IntersectionEvent
+	done chan struct{}
+}
+
+// NewInprocTransport creates an InprocTransport with the given input/output
+// channel buffer size.
+func NewInprocTransport(buffer int) *InprocTransport {
+	return &InprocTransport{
+		in:   make(chan Cxtx, buffer),
+		out:  make(chan IntersectionEvent, buffer),
+		done: make(chan struct{}),
+	}
+}
+
+// Send enqueues a client ciphertext for the StreamingServer to consume. It
+// is the producer-side counterpart to Recv and is meant to be called by
+// whatever feeds ciphertexts into the pipeline (a test, or a real client).
+func (t *InprocTransport) Send(c Cxtx) {
+	t.in <- c
+}
+
+// Close signals that no more ciphertexts will be sent; a subsequent Recv
+// drains any buffered items and then returns ErrTransportClosed.
+func (t *InprocTransport) Close() {
+	close(t.in)
+}
+
+// Recv implements Transport.
+func (t *InprocTransport) Recv() (Cxtx, error) {
+	c, ok := <-t.in
+	if !ok {
+		return Cxtx{}, ErrTransportClosed
+	}
+	return c, nil
+}
+
+// Publish implements Transport.
+func (t *InprocTransport) Publish(hit IntersectionEvent) error {
+	t.out <- hit
+	return nil
+}
+
+// Events returns the channel StreamingServer publishes confirmed
+// intersection hits to.
+func (t *InprocTransport) Events() <-chan IntersectionEvent {
+	return t.out
+}
@@ -0,0 +1,203 @@
+package psi
+
+import (
+	"bufio"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/LE"
+)
+
+// WorkerPolicy bounds CalculateOptimalWorkersWithPolicy's worker count to
+// what the host actually running it can sustain, replacing the constants
+// CalculateOptimalWorkers used to hardcode for one specific dual-socket
+// Xeon Gold 5418Y box. The zero value is usable (CalculateOptimalWorkers
+// falls back field-by-field to AutoDetectPolicy()'s defaults), so existing
+// callers building a WorkerPolicy by hand don't need to fill in every
+// field.
+type WorkerPolicy struct {
+	HardwareLimit     int     // worker ceiling; 0 means runtime.NumCPU()
+	AvailableRAMBytes uint64  // RAM assumed free for in-flight records; 0 disables the RAM constraint
+	MemPerRecordBytes uint64  // estimated per-in-flight-record memory cost; 0 means defaultMemPerRecordBytes
+	SafetyMargin      float64 // multiplier applied to the per-record estimate; 0 means defaultSafetyMargin
+	Min               int     // floor regardless of other constraints; 0 means defaultPracticalMinimum
+	Max               int     // hard ceiling regardless of other constraints; 0 means no extra ceiling
+}
+
+// defaultMemPerRecordBytes is the fallback MemPerRecordBytes for a
+// WorkerPolicy that hasn't been Calibrate'd against a real *LE.LE yet,
+// carried over from CalculateOptimalWorkers' old "~35 MB per record" figure
+// as a starting estimate until a real measurement replaces it.
+const defaultMemPerRecordBytes = 35 * 1024 * 1024
+
+const (
+	defaultSafetyMargin     = 1.15
+	defaultPracticalMinimum = 8
+)
+
+// AutoDetectPolicy builds a WorkerPolicy from the host this process is
+// actually running on: runtime.NumCPU() for HardwareLimit and
+// availableMemoryBytes() for AvailableRAMBytes, instead of
+// CalculateOptimalWorkers' former hardcoded 48-core/117-GB numbers.
+// MemPerRecordBytes starts at defaultMemPerRecordBytes - call Calibrate on
+// the result once a *LE.LE is available to replace it with a measurement
+// taken against this process's actual parameters.
+//
+// PSI_WORKERS, if set to a positive integer, pins both HardwareLimit and
+// Max to that value, skipping CPU detection entirely. PSI_MAX_RAM_GB, if
+// set to a positive number, overrides AvailableRAMBytes instead of reading
+// /proc/meminfo.
+func AutoDetectPolicy() WorkerPolicy {
+	p := WorkerPolicy{
+		HardwareLimit:     runtime.NumCPU(),
+		AvailableRAMBytes: availableMemoryBytes(),
+		MemPerRecordBytes: defaultMemPerRecordBytes,
+		SafetyMargin:      defaultSafetyMargin,
+		Min:               defaultPracticalMinimum,
+	}
+
+	if v := os.Getenv("PSI_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			p.HardwareLimit = n
+			p.Max = n
+		} else {
+			log.Printf("psi: ignoring invalid PSI_WORKERS=%q", v)
+		}
+	}
+	if v := os.Getenv("PSI_MAX_RAM_GB"); v != "" {
+		if gb, err := strconv.ParseFloat(v, 64); err == nil && gb > 0 {
+			p.AvailableRAMBytes = uint64(gb * 1024 * 1024 * 1024)
+		} else {
+			log.Printf("psi: ignoring invalid PSI_MAX_RAM_GB=%q", v)
+		}
+	}
+
+	return p
+}
+
+// Calibrate returns a copy of p with MemPerRecordBytes replaced by an
+// empirical measurement: one le.KeyGen() call, with MemPerRecordBytes set
+// to the resulting runtime.MemStats.HeapAlloc delta. KeyGen is the
+// per-record cost ServerInitialize's worker pool actually pays, so this is
+// a closer estimate than defaultMemPerRecordBytes for the parameters le
+// describes. A no-op if PSI_WORKERS already pinned p.Max, since
+// MemPerRecordBytes wouldn't change the result in that case.
+func (p WorkerPolicy) Calibrate(le *LE.LE) WorkerPolicy {
+	if p.Max > 0 {
+		return p
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	le.KeyGen()
+	runtime.ReadMemStats(&after)
+
+	if after.HeapAlloc > before.HeapAlloc {
+		p.MemPerRecordBytes = after.HeapAlloc - before.HeapAlloc
+	}
+	return p
+}
+
+// availableMemoryBytes reads /proc/meminfo's MemAvailable, the kernel's own
+// estimate of memory available for new allocations without swapping. Falls
+// back to a conservative 4 GiB guess - logged once - when that file can't
+// be read or parsed, which is always the case on non-Linux platforms in
+// this snapshot (a macOS build would read `sysctl hw.memsize`, Windows
+// `GlobalMemoryStatusEx`; neither is wired up here).
+func availableMemoryBytes() uint64 {
+	const fallback = 4 * 1024 * 1024 * 1024
+
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		log.Printf("psi: could not read /proc/meminfo (%v), assuming %d bytes available", err, fallback)
+		return fallback
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "MemAvailable:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			break
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			break
+		}
+		return kb * 1024
+	}
+
+	log.Printf("psi: MemAvailable not found in /proc/meminfo, assuming %d bytes available", fallback)
+	return fallback
+}
+
+// CalculateOptimalWorkersWithPolicy is CalculateOptimalWorkers parameterized
+// by policy instead of hardcoded hardware constants: the same
+// memory/cache/hardware-limit minimization, but every constant comes from
+// policy (falling back field-by-field to AutoDetectPolicy()'s defaults for
+// a zero-valued field, except AvailableRAMBytes, where zero disables the RAM
+// constraint) rather than one box's measurements.
+func CalculateOptimalWorkersWithPolicy(datasetSize int, policy WorkerPolicy) int {
+	hardwareLimit := policy.HardwareLimit
+	if hardwareLimit <= 0 {
+		hardwareLimit = runtime.NumCPU()
+	}
+	memPerRecord := policy.MemPerRecordBytes
+	if memPerRecord == 0 {
+		memPerRecord = defaultMemPerRecordBytes
+	}
+	safetyMargin := policy.SafetyMargin
+	if safetyMargin == 0 {
+		safetyMargin = defaultSafetyMargin
+	}
+	minWorkers := policy.Min
+	if minWorkers <= 0 {
+		minWorkers = defaultPracticalMinimum
+	}
+
+	estimatedMemory := float64(datasetSize) * float64(memPerRecord) * safetyMargin
+	memoryLimit := hardwareLimit
+	if policy.AvailableRAMBytes > 0 && estimatedMemory > float64(policy.AvailableRAMBytes)*0.6 {
+		memoryLimit = int((float64(policy.AvailableRAMBytes) * 0.85) / estimatedMemory * float64(hardwareLimit))
+	}
+
+	cacheLimit := hardwareLimit
+	if datasetSize > 100 {
+		cacheLimit = int(1.5 * math.Sqrt(float64(datasetSize)))
+		if cacheLimit > hardwareLimit {
+			cacheLimit = hardwareLimit
+		}
+		if cacheLimit < 16 {
+			cacheLimit = 16
+		}
+	}
+
+	optimal := memoryLimit
+	if cacheLimit < optimal {
+		optimal = cacheLimit
+	}
+	if hardwareLimit < optimal {
+		optimal = hardwareLimit
+	}
+	if optimal < minWorkers {
+		optimal = minWorkers
+	}
+	if policy.Max > 0 && optimal > policy.Max {
+		optimal = policy.Max
+	}
+
+	estimatedRAMGB := float64(datasetSize) * float64(memPerRecord) / (1024 * 1024 * 1024)
+	log.Printf("msg=\"adaptive worker sizing\" dataset_size=%d workers=%d estimated_ram_gb=%.2f memory_limit=%d cache_limit=%d hardware_limit=%d min=%d max=%d",
+		datasetSize, optimal, estimatedRAMGB, memoryLimit, cacheLimit, hardwareLimit, minWorkers, policy.Max)
+
+	return optimal
+}
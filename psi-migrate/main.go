@@ -0,0 +1,57 @@
+// Command psi-migrate applies, rolls back, or reports the schema version of
+// a witness-tree database offline, without standing up a full
+// ServerInitContext. It drives the same internal/storage/migrations
+// registry ServerInitialize runs automatically on startup.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/SanthoshCheemala/LE-PSI/internal/storage"
+	"github.com/SanthoshCheemala/LE-PSI/internal/storage/migrations"
+)
+
+func main() {
+	dsn := flag.String("db", "", "tree database DSN (sqlite://path, postgres://..., or a bare sqlite path)")
+	op := flag.String("op", "up", "operation to run: up, down, or version")
+	steps := flag.Int("steps", 1, "number of migrations to roll back (down only)")
+	flag.Parse()
+
+	if *dsn == "" {
+		fmt.Fprintln(os.Stderr, "psi-migrate: -db is required")
+		os.Exit(2)
+	}
+
+	backend, err := storage.OpenBackend(*dsn)
+	if err != nil {
+		log.Fatalf("psi-migrate: open backend: %v", err)
+	}
+	defer backend.Close()
+
+	db := backend.Raw()
+
+	switch *op {
+	case "up":
+		if err := migrations.Up(db, backend.Dialect()); err != nil {
+			log.Fatalf("psi-migrate: up: %v", err)
+		}
+	case "down":
+		if err := migrations.Down(db, backend.Dialect(), *steps); err != nil {
+			log.Fatalf("psi-migrate: down: %v", err)
+		}
+	case "version":
+		// Report only; fall through to the version print below.
+	default:
+		fmt.Fprintf(os.Stderr, "psi-migrate: unknown -op %q (want up, down, or version)\n", *op)
+		os.Exit(2)
+	}
+
+	version, err := migrations.CurrentVersion(db)
+	if err != nil {
+		log.Fatalf("psi-migrate: current version: %v", err)
+	}
+	fmt.Printf("schema version: %d\n", version)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	httppprof "net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tracingEnabled mirrors whether -pprof was set: witness-generation traces
+// and the live /debug/psi/current status are only worth the overhead of
+// tracking while someone is actually watching.
+var tracingEnabled bool
+
+// startPprofServer exposes the standard net/http/pprof handlers plus
+// /debug/psi/current, so a maintainer can attach `go tool pprof` or just curl
+// the current test's phase and RAM while a scalability run is in progress,
+// following the pattern in benchmarks/benchmark_main.go's startPprofServer.
+func startPprofServer(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", httppprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+	mux.HandleFunc("/debug/psi/current", debugPSICurrentHandler)
+
+	go func() {
+		log.Printf("pprof server listening on %s", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("pprof server stopped: %v", err)
+		}
+	}()
+}
+
+// currentStatus tracks the in-flight test's name, phase and a live,
+// point-sampled RAMAnalysis, so debugPSICurrentHandler can answer
+// immediately rather than waiting for the test to finish and produce a
+// TestResult. Unlike the RAMAnalysis a bench.Recorder eventually builds from
+// continuous background samples, this one is just whatever the process
+// looked like at the moment of the last phase transition - good enough for
+// "is this test still alive and roughly how much RAM is it using", not a
+// replacement for the post-test report.
+var currentStatus = struct {
+	mu        sync.Mutex
+	testName  string
+	phase     string
+	startedAt time.Time
+	ram       RAMAnalysis
+}{}
+
+// setPhase always records the active test's name and phase - metricssink.go
+// reads currentStatus.phase to tag live line-protocol samples regardless of
+// whether -pprof is set. The live RAMAnalysis point-read is only worth its
+// /proc/self/status read when tracingEnabled (-pprof set), since it exists
+// for debugPSICurrentHandler.
+func setPhase(testName, phase string) {
+	currentStatus.mu.Lock()
+	if currentStatus.testName != testName {
+		currentStatus.testName = testName
+		currentStatus.startedAt = time.Now()
+		currentStatus.ram = RAMAnalysis{}
+	}
+	currentStatus.phase = phase
+	currentStatus.mu.Unlock()
+
+	if !tracingEnabled {
+		return
+	}
+
+	rssMB, _, _ := currentProcessRAMMB()
+
+	currentStatus.mu.Lock()
+	defer currentStatus.mu.Unlock()
+
+	if currentStatus.ram.BaselineRAM_MB == 0 {
+		currentStatus.ram.BaselineRAM_MB = rssMB
+	}
+
+	switch phase {
+	case "data_load_start":
+		currentStatus.ram.AfterDataLoadRAM_MB = rssMB
+		currentStatus.ram.DataLoadRAMDelta_MB = rssMB - currentStatus.ram.BaselineRAM_MB
+	case "server_init_start":
+		currentStatus.ram.AfterServerInitRAM_MB = rssMB
+		currentStatus.ram.ServerInitRAMDelta_MB = rssMB - currentStatus.ram.AfterDataLoadRAM_MB
+	case "encryption_start":
+		currentStatus.ram.AfterEncryptionRAM_MB = rssMB
+		currentStatus.ram.EncryptionRAMDelta_MB = rssMB - currentStatus.ram.AfterServerInitRAM_MB
+	}
+	if rssMB > currentStatus.ram.PeakRAM_MB {
+		currentStatus.ram.PeakRAM_MB = rssMB
+	}
+	currentStatus.ram.TotalRAMDelta_MB = currentStatus.ram.PeakRAM_MB - currentStatus.ram.BaselineRAM_MB
+}
+
+// currentPhase returns the phase set by the most recent setPhase call, for
+// tagging live line-protocol samples in metricssink.go.
+func currentPhase() string {
+	currentStatus.mu.Lock()
+	defer currentStatus.mu.Unlock()
+	return currentStatus.phase
+}
+
+// debugPSICurrentHandler reports the active test's name, phase, elapsed time
+// and live RAMAnalysis as JSON.
+func debugPSICurrentHandler(w http.ResponseWriter, r *http.Request) {
+	currentStatus.mu.Lock()
+	testName, phase, startedAt, ram := currentStatus.testName, currentStatus.phase, currentStatus.startedAt, currentStatus.ram
+	currentStatus.mu.Unlock()
+
+	var elapsedMs int64
+	if !startedAt.IsZero() {
+		elapsedMs = time.Since(startedAt).Milliseconds()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		TestName    string      `json:"test_name"`
+		Phase       string      `json:"phase"`
+		ElapsedMs   int64       `json:"elapsed_ms"`
+		RAMAnalysis RAMAnalysis `json:"ram_analysis"`
+	}{
+		TestName:    testName,
+		Phase:       phase,
+		ElapsedMs:   elapsedMs,
+		RAMAnalysis: ram,
+	})
+}
+
+// currentProcessRAMMB point-reads VmRSS/VmPeak/VmSwap from /proc/self/status,
+// the same source bench.Recorder samples on an interval - this reads it
+// on demand instead, since debugPSICurrentHandler is polled interactively.
+func currentProcessRAMMB() (rssMB, peakMB, swapMB float64) {
+	data, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return 0, 0, 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		switch {
+		case strings.HasPrefix(line, "VmRSS:"):
+			rssMB = parseStatusLineKB(line)
+		case strings.HasPrefix(line, "VmPeak:"):
+			peakMB = parseStatusLineKB(line)
+		case strings.HasPrefix(line, "VmSwap:"):
+			swapMB = parseStatusLineKB(line)
+		}
+	}
+	return rssMB, peakMB, swapMB
+}
+
+func parseStatusLineKB(line string) float64 {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return 0
+	}
+	kb, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0
+	}
+	return kb / 1024
+}
+
+// witnessTrace holds the open trace file for one test's witness-generation
+// phase, so stopWitnessTrace can find it again to close it.
+type witnessTrace struct {
+	f *os.File
+}
+
+// startWitnessTrace starts a runtime/trace capture for testName's witness
+// generation (psi.ServerInitialize), writing it to
+// resultsDirName/trace_<testName>.out. It is a no-op unless tracingEnabled,
+// since runtime/trace has real overhead and this is meant to be opted into
+// with -pprof when diagnosing the witness-generation memory blow-up, not run
+// on every test by default.
+func startWitnessTrace(testName string) *witnessTrace {
+	if !tracingEnabled {
+		return nil
+	}
+
+	path := filepath.Join(resultsDirName, fmt.Sprintf("trace_%s.out", testName))
+	f, err := os.Create(path)
+	if err != nil {
+		log.Printf("witness trace %s: create %s: %v", testName, path, err)
+		return nil
+	}
+	if err := trace.Start(f); err != nil {
+		log.Printf("witness trace %s: start: %v", testName, err)
+		f.Close()
+		return nil
+	}
+	return &witnessTrace{f: f}
+}
+
+// stopWitnessTrace stops and closes a trace started by startWitnessTrace. It
+// is safe to call with a nil wt (tracing disabled or failed to start).
+func stopWitnessTrace(wt *witnessTrace) {
+	if wt == nil {
+		return
+	}
+	trace.Stop()
+	wt.f.Close()
+}
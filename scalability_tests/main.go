@@ -3,18 +3,30 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	httppprof "net/http/pprof"
 	"os"
 	"path/filepath"
 	"runtime"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/SanthoshCheemala/LE-PSI/pkg/bench"
 	"github.com/SanthoshCheemala/LE-PSI/pkg/psi"
 	"github.com/SanthoshCheemala/LE-PSI/utils"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// resultsDirName is where reports, and (when -pprof is set) witness-generation
+// traces, are written.
+const resultsDirName = "scalability_results"
+
 // ScalabilityTest represents a single test configuration
 type ScalabilityTest struct {
 	Name           string
@@ -22,87 +34,132 @@ type ScalabilityTest struct {
 	ClientSize     int
 	OverlapPercent float64
 	Description    string
+	// MaxRAM_MB aborts the test with ErrorMessage "ram_threshold_exceeded"
+	// once process RSS crosses it, instead of letting it run the host out
+	// of memory. Zero disables the check.
+	MaxRAM_MB float64
 }
 
 // TestResult stores the results of a scalability test
 type TestResult struct {
-	TestName             string        `json:"test_name"`
-	ServerDatasetSize    int           `json:"server_dataset_size"`
-	ClientDatasetSize    int           `json:"client_dataset_size"`
-	OverlapSize          int           `json:"overlap_size"`
-	OverlapPercent       float64       `json:"overlap_percent"`
-	MatchesFound         int           `json:"matches_found"`
-	Accuracy             float64       `json:"accuracy"`
-	InitializationTime   time.Duration `json:"initialization_time_ns"`
-	EncryptionTime       time.Duration `json:"encryption_time_ns"`
-	IntersectionTime     time.Duration `json:"intersection_time_ns"`
-	TotalTime            time.Duration `json:"total_time_ns"`
-	Throughput           float64       `json:"throughput_ops_per_sec"`
-	MemoryEstimate       int64         `json:"memory_estimate_bytes"`
-	Success              bool          `json:"success"`
-	ErrorMessage         string        `json:"error_message,omitempty"`
-	CryptographicParams  CryptoParams  `json:"cryptographic_params"`
-	GoRuntimeStats       GoStats       `json:"go_runtime_stats"`
-	RAMAnalysis          RAMAnalysis   `json:"ram_analysis"`
+	TestName            string            `json:"test_name"`
+	ServerDatasetSize   int               `json:"server_dataset_size"`
+	ClientDatasetSize   int               `json:"client_dataset_size"`
+	OverlapSize         int               `json:"overlap_size"`
+	OverlapPercent      float64           `json:"overlap_percent"`
+	MatchesFound        int               `json:"matches_found"`
+	Accuracy            float64           `json:"accuracy"`
+	InitializationTime  time.Duration     `json:"initialization_time_ns"`
+	EncryptionTime      time.Duration     `json:"encryption_time_ns"`
+	IntersectionTime    time.Duration     `json:"intersection_time_ns"`
+	TotalTime           time.Duration     `json:"total_time_ns"`
+	Throughput          float64           `json:"throughput_ops_per_sec"`
+	MemoryEstimate      int64             `json:"memory_estimate_bytes"`
+	Success             bool              `json:"success"`
+	ErrorMessage        string            `json:"error_message,omitempty"`
+	CryptographicParams CryptoParams      `json:"cryptographic_params"`
+	GoRuntimeStats      GoStats           `json:"go_runtime_stats"`
+	RuntimeTimeline     []GoStatsSnapshot `json:"runtime_timeline,omitempty"`
+	GCPauseHistogram    []HistogramBucket `json:"gc_pause_histogram,omitempty"`
+	RuntimeUsagePhases  []bench.Phase     `json:"runtime_usage_phases,omitempty"`
+	RAMAnalysis         RAMAnalysis       `json:"ram_analysis"`
 }
 
 // RAMAnalysis tracks RAM usage at different stages of PSI execution
 type RAMAnalysis struct {
 	// Baseline memory before test starts
-	BaselineRAM_MB           float64 `json:"baseline_ram_mb"`
-	
+	BaselineRAM_MB float64 `json:"baseline_ram_mb"`
+
 	// Memory after loading data
-	AfterDataLoadRAM_MB      float64 `json:"after_data_load_ram_mb"`
-	DataLoadRAMDelta_MB      float64 `json:"data_load_ram_delta_mb"`
-	
+	AfterDataLoadRAM_MB float64 `json:"after_data_load_ram_mb"`
+	DataLoadRAMDelta_MB float64 `json:"data_load_ram_delta_mb"`
+
 	// Memory after server initialization (witness generation)
-	AfterServerInitRAM_MB    float64 `json:"after_server_init_ram_mb"`
-	ServerInitRAMDelta_MB    float64 `json:"server_init_ram_delta_mb"`
-	
+	AfterServerInitRAM_MB float64 `json:"after_server_init_ram_mb"`
+	ServerInitRAMDelta_MB float64 `json:"server_init_ram_delta_mb"`
+
 	// Memory after client encryption
-	AfterEncryptionRAM_MB    float64 `json:"after_encryption_ram_mb"`
-	EncryptionRAMDelta_MB    float64 `json:"encryption_ram_delta_mb"`
-	
+	AfterEncryptionRAM_MB float64 `json:"after_encryption_ram_mb"`
+	EncryptionRAMDelta_MB float64 `json:"encryption_ram_delta_mb"`
+
 	// Peak memory during test
-	PeakRAM_MB               float64 `json:"peak_ram_mb"`
-	TotalRAMDelta_MB         float64 `json:"total_ram_delta_mb"`
-	
+	PeakRAM_MB       float64 `json:"peak_ram_mb"`
+	TotalRAMDelta_MB float64 `json:"total_ram_delta_mb"`
+
 	// Per-record RAM metrics
-	RAMPerServerRecord_MB    float64 `json:"ram_per_server_record_mb"`
-	RAMPerClientRecord_MB    float64 `json:"ram_per_client_record_mb"`
-	
+	RAMPerServerRecord_MB float64 `json:"ram_per_server_record_mb"`
+	RAMPerClientRecord_MB float64 `json:"ram_per_client_record_mb"`
+
 	// System memory info
-	SystemTotalRAM_MB        float64 `json:"system_total_ram_mb"`
-	RAMUsagePercent          float64 `json:"ram_usage_percent"`
+	SystemTotalRAM_MB float64 `json:"system_total_ram_mb"`
+	RAMUsagePercent   float64 `json:"ram_usage_percent"`
+
+	// OS-level process memory, from /proc/self/status - this is what
+	// actually determines OOM risk, since HeapAlloc/HeapInUse only cover
+	// the Go-managed heap and miss the lattice ring/matrix buffers and
+	// mmap'd SQLite pages that dominate witness generation's footprint.
+	ProcessRSS_MB    float64 `json:"process_rss_mb"`
+	ProcessVmPeak_MB float64 `json:"process_vm_peak_mb"`
+	ProcessSwap_MB   float64 `json:"process_swap_mb"`
+
+	// Cgroup memory accounting, when running under one (v2 preferred,
+	// falling back to v1); both are 0 outside a cgroup.
+	CgroupMemoryCurrent_MB float64 `json:"cgroup_memory_current_mb,omitempty"`
+	CgroupMemoryMax_MB     float64 `json:"cgroup_memory_max_mb,omitempty"`
 }
 
-// GoStats stores Go runtime performance metrics
+// GoStats stores Go runtime performance metrics, sampled from the
+// runtime/metrics package rather than runtime.ReadMemStats - see
+// collectGoRuntimeStats in runtime_stats.go.
 type GoStats struct {
 	// Memory Statistics
-	AllocatedMemoryMB    float64 `json:"allocated_memory_mb"`
-	TotalAllocatedMB     float64 `json:"total_allocated_mb"`
-	SystemMemoryMB       float64 `json:"system_memory_mb"`
-	HeapAllocMB          float64 `json:"heap_alloc_mb"`
-	HeapSysMB            float64 `json:"heap_sys_mb"`
-	HeapIdleMB           float64 `json:"heap_idle_mb"`
-	HeapInUseMB          float64 `json:"heap_inuse_mb"`
-	StackInUseMB         float64 `json:"stack_inuse_mb"`
-	
+	AllocatedMemoryMB float64 `json:"allocated_memory_mb"`
+	TotalAllocatedMB  float64 `json:"total_allocated_mb"`
+	SystemMemoryMB    float64 `json:"system_memory_mb"`
+	HeapAllocMB       float64 `json:"heap_alloc_mb"`
+	HeapSysMB         float64 `json:"heap_sys_mb"`
+	HeapIdleMB        float64 `json:"heap_idle_mb"`
+	HeapInUseMB       float64 `json:"heap_inuse_mb"`
+	HeapGoalMB        float64 `json:"heap_goal_mb"`
+	StackInUseMB      float64 `json:"stack_inuse_mb"`
+
 	// Garbage Collection Statistics
-	NumGC                uint32  `json:"num_gc"`
-	GCCPUPercentage      float64 `json:"gc_cpu_percentage"`
-	LastGCPauseMs        float64 `json:"last_gc_pause_ms"`
-	TotalGCPauseMs       float64 `json:"total_gc_pause_ms"`
-	
+	NumGC           uint64  `json:"num_gc"`
+	GCCPUPercentage float64 `json:"gc_cpu_percentage"`
+	// LastGCPauseMs approximates the most recent pause with the p50 bucket
+	// of the /gc/pauses:seconds histogram, since runtime/metrics exposes
+	// only the distribution, not the single most recent sample.
+	LastGCPauseMs  float64 `json:"last_gc_pause_ms"`
+	TotalGCPauseMs float64 `json:"total_gc_pause_ms"`
+
+	// Tail-latency distributions runtime.ReadMemStats cannot express at
+	// all, sourced from the /gc/pauses:seconds and /sched/latencies:seconds
+	// histograms.
+	GCPauseP50Ms      float64 `json:"gc_pause_p50_ms"`
+	GCPauseP90Ms      float64 `json:"gc_pause_p90_ms"`
+	GCPauseP99Ms      float64 `json:"gc_pause_p99_ms"`
+	SchedLatencyP50Ms float64 `json:"sched_latency_p50_ms"`
+	SchedLatencyP90Ms float64 `json:"sched_latency_p90_ms"`
+	SchedLatencyP99Ms float64 `json:"sched_latency_p99_ms"`
+	MutexWaitTotalMs  float64 `json:"mutex_wait_total_ms"`
+
 	// Goroutine and CPU Statistics
-	NumGoroutines        int     `json:"num_goroutines"`
-	NumCPU               int     `json:"num_cpu"`
-	GOMAXPROCS           int     `json:"gomaxprocs"`
-	
+	NumGoroutines int `json:"num_goroutines"`
+	NumCPU        int `json:"num_cpu"`
+	GOMAXPROCS    int `json:"gomaxprocs"`
+
 	// Memory Allocation Statistics
-	Mallocs              uint64  `json:"mallocs"`
-	Frees                uint64  `json:"frees"`
-	LiveObjects          uint64  `json:"live_objects"`
+	Mallocs     uint64 `json:"mallocs"`
+	Frees       uint64 `json:"frees"`
+	LiveObjects uint64 `json:"live_objects"`
+}
+
+// GoStatsSnapshot is one collectGoRuntimeStats reading with a timestamp, so
+// the HTML report can plot how GoStats evolved over the course of a test
+// rather than only inspecting a single before/after pair.
+type GoStatsSnapshot struct {
+	ElapsedMs float64 `json:"elapsed_ms"`
+	Stats     GoStats `json:"stats"`
 }
 
 // CryptoParams stores cryptographic parameters
@@ -117,31 +174,47 @@ type CryptoParams struct {
 
 // ScalabilityReport aggregates all test results
 type ScalabilityReport struct {
-	Timestamp      string       `json:"timestamp"`
-	TotalTests     int          `json:"total_tests"`
-	SuccessfulTests int         `json:"successful_tests"`
-	FailedTests    int          `json:"failed_tests"`
-	TestResults    []TestResult `json:"test_results"`
-	Summary        Summary      `json:"summary"`
+	Timestamp       string       `json:"timestamp"`
+	TotalTests      int          `json:"total_tests"`
+	SuccessfulTests int          `json:"successful_tests"`
+	FailedTests     int          `json:"failed_tests"`
+	TestResults     []TestResult `json:"test_results"`
+	Summary         Summary      `json:"summary"`
+}
+
+// HistoryIndex lists every scalability_test_*.json report written to a
+// results directory, oldest first, so the HTML report can fetch and plot
+// the full run history instead of only the run it was generated for - see
+// writeHistoryIndex.
+type HistoryIndex struct {
+	Runs []HistoryRunRef `json:"runs"`
+}
+
+// HistoryRunRef is one entry in a HistoryIndex: a report's timestamp (as
+// embedded in its filename) and the JSON filename itself, relative to the
+// results directory.
+type HistoryRunRef struct {
+	Timestamp string `json:"timestamp"`
+	JSONFile  string `json:"json_file"`
 }
 
 // Summary provides aggregate statistics
 type Summary struct {
-	TotalDataProcessed     int     `json:"total_data_processed"`
-	TotalMatchesFound      int     `json:"total_matches_found"`
-	AverageAccuracy        float64 `json:"average_accuracy"`
-	AverageThroughput      float64 `json:"average_throughput_ops_per_sec"`
-	TotalExecutionTime     string  `json:"total_execution_time"`
-	FastestTest            string  `json:"fastest_test"`
-	SlowestTest            string  `json:"slowest_test"`
-	LargestDatasetTested   int     `json:"largest_dataset_tested"`
-	ScalabilityScore       float64 `json:"scalability_score"`
-	
+	TotalDataProcessed   int     `json:"total_data_processed"`
+	TotalMatchesFound    int     `json:"total_matches_found"`
+	AverageAccuracy      float64 `json:"average_accuracy"`
+	AverageThroughput    float64 `json:"average_throughput_ops_per_sec"`
+	TotalExecutionTime   string  `json:"total_execution_time"`
+	FastestTest          string  `json:"fastest_test"`
+	SlowestTest          string  `json:"slowest_test"`
+	LargestDatasetTested int     `json:"largest_dataset_tested"`
+	ScalabilityScore     float64 `json:"scalability_score"`
+
 	// RAM Analysis Summary
-	AverageRAMPerServerRecord_MB   float64 `json:"avg_ram_per_server_record_mb"`
-	AverageRAMPerClientRecord_MB   float64 `json:"avg_ram_per_client_record_mb"`
-	PeakRAMUsed_MB                 float64 `json:"peak_ram_used_mb"`
-	RAMScalingFactor               float64 `json:"ram_scaling_factor"`
+	AverageRAMPerServerRecord_MB float64 `json:"avg_ram_per_server_record_mb"`
+	AverageRAMPerClientRecord_MB float64 `json:"avg_ram_per_client_record_mb"`
+	PeakRAMUsed_MB               float64 `json:"peak_ram_used_mb"`
+	RAMScalingFactor             float64 `json:"ram_scaling_factor"`
 }
 
 // Transaction represents a data record
@@ -154,6 +227,23 @@ type Transaction struct {
 }
 
 func main() {
+	pprofAddr := flag.String("pprof", "", "If set, serve net/http/pprof plus /debug/psi/current on this address (e.g. localhost:6060), and trace witness generation to "+resultsDirName+"/trace_<TestName>.out")
+	flag.Float64Var(&cgroupAbortFraction, "cgroup-abort-fraction", defaultCgroupAbortFraction, "Abort a test when its cgroup memory.current/memory.max ratio exceeds this fraction")
+	metricsSink := flag.String("metrics-sink", "", "If set, stream live RAM/GoStats samples and per-test phase timings as InfluxDB line protocol to this destination: a file path, tcp://host:port, or udp://host:port")
+	liveAddr := flag.String("live", "", "If set, serve a live dashboard on this address (e.g. localhost:8090) that streams each completed TestResult and periodic runtime.MemStats snapshots over Server-Sent Events as the sweep runs, instead of only producing a report once it finishes")
+	reportLang := flag.String("report-lang", "en", "Default UI language for the generated HTML report (en, zh, es, fr); every dictionary is embedded regardless, so a language switcher in the report itself can relocalize it in-browser without regenerating")
+	flag.Parse()
+
+	if *metricsSink != "" {
+		openMetricsSink(*metricsSink)
+		defer closeMetricsSink()
+	}
+
+	if *pprofAddr != "" {
+		tracingEnabled = true
+		startPprofServer(*pprofAddr)
+	}
+
 	fmt.Println("=================================================")
 	fmt.Println("  LE-PSI SCALABILITY TESTING FRAMEWORK")
 	fmt.Println("  Testing PSI on Large Datasets")
@@ -203,11 +293,18 @@ func main() {
 	}
 
 	// Create results directory
-	resultsDir := "scalability_results"
+	resultsDir := resultsDirName
 	if err := os.MkdirAll(resultsDir, 0755); err != nil {
 		log.Fatalf("Failed to create results directory: %v", err)
 	}
 
+	var liveResultsCh chan TestResult
+	if *liveAddr != "" {
+		liveResultsCh = make(chan TestResult, len(tests))
+		go ServeLiveReport(*liveAddr, liveResultsCh)
+		fmt.Printf("Live dashboard: http://%s\n\n", *liveAddr)
+	}
+
 	// Run all tests
 	report := ScalabilityReport{
 		Timestamp:   time.Now().Format("2006-01-02_15-04-05"),
@@ -222,6 +319,9 @@ func main() {
 
 		result := runScalabilityTest(test)
 		report.TestResults = append(report.TestResults, result)
+		if liveResultsCh != nil {
+			liveResultsCh <- result
+		}
 
 		if result.Success {
 			report.SuccessfulTests++
@@ -233,6 +333,9 @@ func main() {
 		}
 		fmt.Println()
 	}
+	if liveResultsCh != nil {
+		close(liveResultsCh)
+	}
 
 	report.TotalTests = len(tests)
 	report.Summary = generateSummary(report.TestResults)
@@ -249,8 +352,14 @@ func main() {
 		fmt.Printf("‚úì JSON report saved: %s\n", jsonPath)
 	}
 
+	// Update the history index so the HTML report can plot this run
+	// alongside every prior run in resultsDir.
+	if err := writeHistoryIndex(resultsDir); err != nil {
+		log.Printf("Error writing history index: %v", err)
+	}
+
 	// Generate HTML report
-	if err := generateHTMLReport(htmlPath, jsonPath); err != nil {
+	if err := generateHTMLReport(htmlPath, *reportLang); err != nil {
 		log.Printf("Error generating HTML report: %v", err)
 	} else {
 		fmt.Printf("‚úì HTML report saved: %s\n", htmlPath)
@@ -273,8 +382,8 @@ func main() {
 	fmt.Println("=================================================")
 }
 
-func runScalabilityTest(test ScalabilityTest) TestResult {
-	result := TestResult{
+func runScalabilityTest(test ScalabilityTest) (result TestResult) {
+	result = TestResult{
 		TestName: test.Name,
 		Success:  false,
 	}
@@ -283,13 +392,93 @@ func runScalabilityTest(test ScalabilityTest) TestResult {
 
 	// Force GC before starting to get clean baseline
 	forceGC()
-	
-	// Track RAM: Baseline
-	baselineRAM := getCurrentRAM_MB()
-	result.RAMAnalysis.BaselineRAM_MB = baselineRAM
-	peakRAM := baselineRAM
+	setPhase(test.Name, "baseline")
+
+	// Sample GoStats every ~100ms for the duration of the test, so the HTML
+	// report can plot GC goal vs. heap-in-use over time instead of only
+	// inspecting the single post-test snapshot below.
+	sampler := NewRuntimeSampler(100 * time.Millisecond)
+	defer func() {
+		result.RuntimeTimeline = sampler.Stop()
+	}()
+
+	// Watches RSS and cgroup memory pressure in the background so the test
+	// can be aborted before the host OOMs, rather than crashing it.
+	guard := newRAMGuard(test.MaxRAM_MB)
+	defer guard.Stop()
+
+	// abortIfRAMExceeded is checked at each phase boundary below: the guard
+	// can only be polled between the synchronous psi calls, not inside them,
+	// so a breach during witness generation is caught at the next boundary
+	// rather than the instant it happens.
+	abortIfRAMExceeded := func() bool {
+		exceeded, reason := guard.Exceeded()
+		if !exceeded {
+			return false
+		}
+		log.Printf("%s: aborting, %s", test.Name, reason)
+		result.ErrorMessage = "ram_threshold_exceeded"
+		runtime.GC()
+		return true
+	}
+
+	// RAMAnalysis used to come from getCurrentRAM_MB() point reads at four
+	// phase boundaries, which hid whatever peak happened between them - the
+	// actual cause of the OOMs these comments originally flagged. A
+	// bench.Recorder instead samples continuously in the background, and
+	// bench.Summarize reduces those samples into one bench.Phase per marker
+	// below, so RAMAnalysis is now built from real per-phase min/avg/p99/peak
+	// instead of a single point.
+	recLogPath := fmt.Sprintf("test_%s.bench.log", test.Name)
+	rec, err := bench.NewRecorder(recLogPath, 50*time.Millisecond)
+	if err != nil {
+		result.ErrorMessage = fmt.Sprintf("start bench recorder: %v", err)
+		return result
+	}
+	wireMetricsSink(test, rec, sampler, currentPhase)
+	var phases []bench.Phase
+	defer func() {
+		rec.Close()
+		defer os.Remove(recLogPath)
+
+		player, err := bench.OpenPlayer(recLogPath)
+		if err != nil {
+			log.Printf("warning: open bench log for summary: %v", err)
+			return
+		}
+		defer player.Close()
+
+		records, errs := player.Play()
+		phases = bench.Summarize(records)
+		if err := <-errs; err != nil {
+			log.Printf("warning: replay bench log: %v", err)
+		}
+
+		result.RuntimeUsagePhases = phases
+		result.RAMAnalysis = ramAnalysisFromPhases(phases)
+
+		if result.ServerDatasetSize > 0 {
+			result.RAMAnalysis.RAMPerServerRecord_MB = result.RAMAnalysis.ServerInitRAMDelta_MB / float64(result.ServerDatasetSize)
+		}
+		if result.ClientDatasetSize > 0 {
+			result.RAMAnalysis.RAMPerClientRecord_MB = result.RAMAnalysis.EncryptionRAMDelta_MB / float64(result.ClientDatasetSize)
+		}
+
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		result.RAMAnalysis.SystemTotalRAM_MB = float64(m.Sys) / 1024 / 1024
+		if result.RAMAnalysis.SystemTotalRAM_MB > 0 {
+			result.RAMAnalysis.RAMUsagePercent = (result.RAMAnalysis.PeakRAM_MB / result.RAMAnalysis.SystemTotalRAM_MB) * 100
+		}
+	}()
+
+	if abortIfRAMExceeded() {
+		return result
+	}
 
 	// Load data from database ONLY - no synthetic data
+	rec.Mark("data_load_start")
+	setPhase(test.Name, "data_load_start")
 	serverData, clientData, expectedMatches := loadFromDatabase(test.ServerSize, test.ClientSize)
 	result.ServerDatasetSize = len(serverData)
 	result.ClientDatasetSize = len(clientData)
@@ -299,14 +488,6 @@ func runScalabilityTest(test ScalabilityTest) TestResult {
 		result.OverlapPercent = float64(expectedMatches) / float64(result.ClientDatasetSize) * 100
 	}
 
-	// Track RAM: After data load
-	afterDataLoadRAM := getCurrentRAM_MB()
-	result.RAMAnalysis.AfterDataLoadRAM_MB = afterDataLoadRAM
-	result.RAMAnalysis.DataLoadRAMDelta_MB = afterDataLoadRAM - baselineRAM
-	if afterDataLoadRAM > peakRAM {
-		peakRAM = afterDataLoadRAM
-	}
-
 	// Prepare data
 	serverStrings, err := utils.PrepareDataForPSI(serverData)
 	if err != nil {
@@ -323,22 +504,26 @@ func runScalabilityTest(test ScalabilityTest) TestResult {
 	serverHashes := utils.HashDataPoints(serverStrings)
 	clientHashes := utils.HashDataPoints(clientStrings)
 
+	if abortIfRAMExceeded() {
+		return result
+	}
+
 	// Step 1: Server Initialization (WITNESS GENERATION - MAIN RAM CONSUMER)
+	rec.Mark("server_init_start")
+	setPhase(test.Name, "server_init_start")
 	initStart := time.Now()
 	dbPath := fmt.Sprintf("test_%s.db", test.Name)
+	wt := startWitnessTrace(test.Name)
 	ctx, err := psi.ServerInitialize(serverHashes, dbPath)
+	stopWitnessTrace(wt)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("Server initialization failed: %v", err)
 		return result
 	}
 	result.InitializationTime = time.Since(initStart)
 
-	// Track RAM: After server initialization (witnesses generated)
-	afterServerInitRAM := getCurrentRAM_MB()
-	result.RAMAnalysis.AfterServerInitRAM_MB = afterServerInitRAM
-	result.RAMAnalysis.ServerInitRAMDelta_MB = afterServerInitRAM - afterDataLoadRAM
-	if afterServerInitRAM > peakRAM {
-		peakRAM = afterServerInitRAM
+	if abortIfRAMExceeded() {
+		return result
 	}
 
 	// Clean up database after test
@@ -349,19 +534,19 @@ func runScalabilityTest(test ScalabilityTest) TestResult {
 	result.CryptographicParams = extractCryptoParams(ctx)
 
 	// Step 2: Client Encryption
+	rec.Mark("encryption_start")
+	setPhase(test.Name, "encryption_start")
 	encStart := time.Now()
 	ciphertexts := psi.ClientEncrypt(clientHashes, pp, msg, le)
 	result.EncryptionTime = time.Since(encStart)
 
-	// Track RAM: After encryption
-	afterEncryptionRAM := getCurrentRAM_MB()
-	result.RAMAnalysis.AfterEncryptionRAM_MB = afterEncryptionRAM
-	result.RAMAnalysis.EncryptionRAMDelta_MB = afterEncryptionRAM - afterServerInitRAM
-	if afterEncryptionRAM > peakRAM {
-		peakRAM = afterEncryptionRAM
+	if abortIfRAMExceeded() {
+		return result
 	}
 
 	// Step 3: Intersection Detection
+	rec.Mark("intersection_start")
+	setPhase(test.Name, "intersection_start")
 	intStart := time.Now()
 	matches, err := psi.DetectIntersectionWithContext(ctx, ciphertexts)
 	if err != nil {
@@ -369,17 +554,12 @@ func runScalabilityTest(test ScalabilityTest) TestResult {
 		return result
 	}
 	result.IntersectionTime = time.Since(intStart)
-
-	// Track final RAM
-	finalRAM := getCurrentRAM_MB()
-	if finalRAM > peakRAM {
-		peakRAM = finalRAM
-	}
+	emitPhaseTimings(test, result)
 
 	// Calculate metrics
 	result.TotalTime = time.Since(startTime)
 	result.MatchesFound = len(matches)
-	
+
 	if expectedMatches > 0 {
 		result.Accuracy = float64(result.MatchesFound) / float64(expectedMatches) * 100
 	} else {
@@ -390,23 +570,9 @@ func runScalabilityTest(test ScalabilityTest) TestResult {
 		result.Throughput = float64(result.ClientDatasetSize) / result.TotalTime.Seconds()
 	}
 
-	// Finalize RAM analysis
-	result.RAMAnalysis.PeakRAM_MB = peakRAM
-	result.RAMAnalysis.TotalRAMDelta_MB = peakRAM - baselineRAM
-	
-	// Calculate per-record RAM metrics
-	if result.ServerDatasetSize > 0 {
-		result.RAMAnalysis.RAMPerServerRecord_MB = result.RAMAnalysis.ServerInitRAMDelta_MB / float64(result.ServerDatasetSize)
-	}
-	if result.ClientDatasetSize > 0 {
-		result.RAMAnalysis.RAMPerClientRecord_MB = result.RAMAnalysis.EncryptionRAMDelta_MB / float64(result.ClientDatasetSize)
-	}
-	
-	// Get system memory info
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	result.RAMAnalysis.SystemTotalRAM_MB = float64(m.Sys) / 1024 / 1024
-	result.RAMAnalysis.RAMUsagePercent = (peakRAM / result.RAMAnalysis.SystemTotalRAM_MB) * 100
+	// RAMAnalysis itself (including its per-record and system-memory fields)
+	// is finalized in the bench.Recorder defer above, once the full set of
+	// phases is available.
 
 	// Estimate memory usage
 	result.MemoryEstimate = estimateMemoryUsage(
@@ -418,6 +584,7 @@ func runScalabilityTest(test ScalabilityTest) TestResult {
 
 	// Collect Go runtime statistics
 	result.GoRuntimeStats = collectGoRuntimeStats()
+	result.GCPauseHistogram = gcPauseHistogramBuckets()
 
 	result.Success = true
 	return result
@@ -425,7 +592,7 @@ func runScalabilityTest(test ScalabilityTest) TestResult {
 
 func loadFromDatabase(serverSize, clientSize int) ([]interface{}, []interface{}, int) {
 	dbPath := "../data/transactions.db"
-	
+
 	// Check if database exists
 	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
 		log.Fatalf("ERROR: Database %s not found! Cannot run tests without real data.", dbPath)
@@ -440,7 +607,7 @@ func loadFromDatabase(serverSize, clientSize int) ([]interface{}, []interface{},
 
 	// Load server data from database with specified limit
 	fmt.Printf("Loading %d records from transactions.db...\n", serverSize)
-	
+
 	query := fmt.Sprintf("SELECT * FROM finanical_transactions LIMIT %d", serverSize)
 	rows, err := db.Query(query)
 	if err != nil {
@@ -483,10 +650,10 @@ func loadFromDatabase(serverSize, clientSize int) ([]interface{}, []interface{},
 			}
 			rowData[col] = v
 		}
-		
+
 		serverData = append(serverData, rowData)
 	}
-	
+
 	if len(serverData) == 0 {
 		log.Fatalf("ERROR: No data loaded from database!")
 	}
@@ -496,7 +663,7 @@ func loadFromDatabase(serverSize, clientSize int) ([]interface{}, []interface{},
 	// Create client dataset as a subset of server data (for realistic overlap)
 	clientData := make([]interface{}, clientSize)
 	overlapSize := clientSize // All client data overlaps with server
-	
+
 	for i := 0; i < clientSize; i++ {
 		if i < len(serverData) {
 			clientData[i] = serverData[i]
@@ -521,56 +688,62 @@ func extractCryptoParams(ctx *psi.ServerInitContext) CryptoParams {
 	}
 }
 
-// collectGoRuntimeStats gathers Go runtime performance metrics
-func collectGoRuntimeStats() GoStats {
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	
-	stats := GoStats{
-		// Memory Statistics (convert to MB)
-		AllocatedMemoryMB: float64(m.Alloc) / 1024 / 1024,
-		TotalAllocatedMB:  float64(m.TotalAlloc) / 1024 / 1024,
-		SystemMemoryMB:    float64(m.Sys) / 1024 / 1024,
-		HeapAllocMB:       float64(m.HeapAlloc) / 1024 / 1024,
-		HeapSysMB:         float64(m.HeapSys) / 1024 / 1024,
-		HeapIdleMB:        float64(m.HeapIdle) / 1024 / 1024,
-		HeapInUseMB:       float64(m.HeapInuse) / 1024 / 1024,
-		StackInUseMB:      float64(m.StackInuse) / 1024 / 1024,
-		
-		// Garbage Collection Statistics
-		NumGC:         m.NumGC,
-		GCCPUPercentage: m.GCCPUFraction * 100,
-		
-		// Goroutine and CPU Statistics
-		NumGoroutines: runtime.NumGoroutine(),
-		NumCPU:        runtime.NumCPU(),
-		GOMAXPROCS:    runtime.GOMAXPROCS(0),
-		
-		// Memory Allocation Statistics
-		Mallocs:      m.Mallocs,
-		Frees:        m.Frees,
-		LiveObjects:  m.Mallocs - m.Frees,
-	}
-	
-	// Calculate GC pause times
-	if m.NumGC > 0 {
-		// Last GC pause
-		stats.LastGCPauseMs = float64(m.PauseNs[(m.NumGC+255)%256]) / 1000000
-		
-		// Total GC pause time
-		for _, pause := range m.PauseNs {
-			stats.TotalGCPauseMs += float64(pause) / 1000000
+// phaseRAM finds the named bench.Phase's VmRSSMB peak, or 0 if the phase
+// isn't present (e.g. the test failed before reaching it).
+func phaseRAM(phases []bench.Phase, name string) float64 {
+	for _, p := range phases {
+		if p.Name == name {
+			return p.VmRSSMB.Peak
 		}
 	}
-	
-	return stats
+	return 0
 }
 
-// getCurrentRAM_MB returns current heap memory usage in MB
-func getCurrentRAM_MB() float64 {
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	return float64(m.HeapAlloc) / 1024 / 1024
+// ramAnalysisFromPhases builds RAMAnalysis from a bench.Recorder's phase
+// breakdown: each AfterXRAM_MB/XRAMDelta_MB pair now reflects that phase's
+// peak VmRSS rather than a single getCurrentRAM_MB() read taken right at
+// the phase boundary, so a spike between samples is no longer invisible.
+func ramAnalysisFromPhases(phases []bench.Phase) RAMAnalysis {
+	baseline := phaseRAM(phases, bench.UnmarkedPhase)
+	afterDataLoad := phaseRAM(phases, "data_load_start")
+	afterServerInit := phaseRAM(phases, "server_init_start")
+	afterEncryption := phaseRAM(phases, "encryption_start")
+
+	var peak, peakVmPeak, peakSwap, cgroupCurrent, cgroupMax float64
+	for _, p := range phases {
+		if p.VmRSSMB.Peak > peak {
+			peak = p.VmRSSMB.Peak
+		}
+		if p.VmPeakMB.Peak > peakVmPeak {
+			peakVmPeak = p.VmPeakMB.Peak
+		}
+		if p.VmSwapMB.Peak > peakSwap {
+			peakSwap = p.VmSwapMB.Peak
+		}
+		if p.CgroupMemoryCurrentMB.Peak > cgroupCurrent {
+			cgroupCurrent = p.CgroupMemoryCurrentMB.Peak
+		}
+		if p.CgroupMemoryMaxMB.Peak > cgroupMax {
+			cgroupMax = p.CgroupMemoryMaxMB.Peak
+		}
+	}
+
+	return RAMAnalysis{
+		BaselineRAM_MB:         baseline,
+		AfterDataLoadRAM_MB:    afterDataLoad,
+		DataLoadRAMDelta_MB:    afterDataLoad - baseline,
+		AfterServerInitRAM_MB:  afterServerInit,
+		ServerInitRAMDelta_MB:  afterServerInit - afterDataLoad,
+		AfterEncryptionRAM_MB:  afterEncryption,
+		EncryptionRAMDelta_MB:  afterEncryption - afterServerInit,
+		PeakRAM_MB:             peak,
+		TotalRAMDelta_MB:       peak - baseline,
+		ProcessRSS_MB:          peak,
+		ProcessVmPeak_MB:       peakVmPeak,
+		ProcessSwap_MB:         peakSwap,
+		CgroupMemoryCurrent_MB: cgroupCurrent,
+		CgroupMemoryMax_MB:     cgroupMax,
+	}
 }
 
 // forceGC forces garbage collection and waits for it to complete
@@ -585,13 +758,13 @@ func estimateMemoryUsage(ringDim, matrixSize, layers, datasetSize int) int64 {
 	matrixMemory := polySize * int64(matrixSize*matrixSize)
 	treeMemory := polySize * int64(1<<layers)
 	datasetMemory := int64(datasetSize * 32) // Rough estimate per data point
-	
+
 	return matrixMemory*6 + treeMemory + datasetMemory
 }
 
 func generateSummary(results []TestResult) Summary {
 	var summary Summary
-	
+
 	var totalAccuracy float64
 	var totalThroughput float64
 	var totalExecTime time.Duration
@@ -599,27 +772,27 @@ func generateSummary(results []TestResult) Summary {
 	var slowestTime time.Duration
 	var fastestTest, slowestTest string
 	var maxDataset int
-	
+
 	// RAM analysis variables
 	var totalRAMPerServerRecord float64
 	var totalRAMPerClientRecord float64
 	var peakRAM float64
 	var ramRecordCount int
-	
+
 	successCount := 0
-	
+
 	for _, result := range results {
 		if !result.Success {
 			continue
 		}
-		
+
 		successCount++
 		summary.TotalDataProcessed += result.ClientDatasetSize
 		summary.TotalMatchesFound += result.MatchesFound
 		totalAccuracy += result.Accuracy
 		totalThroughput += result.Throughput
 		totalExecTime += result.TotalTime
-		
+
 		// RAM analysis
 		if result.RAMAnalysis.RAMPerServerRecord_MB > 0 {
 			totalRAMPerServerRecord += result.RAMAnalysis.RAMPerServerRecord_MB
@@ -631,34 +804,34 @@ func generateSummary(results []TestResult) Summary {
 		if result.RAMAnalysis.PeakRAM_MB > peakRAM {
 			peakRAM = result.RAMAnalysis.PeakRAM_MB
 		}
-		
+
 		if result.TotalTime < fastestTime {
 			fastestTime = result.TotalTime
 			fastestTest = result.TestName
 		}
-		
+
 		if result.TotalTime > slowestTime {
 			slowestTime = result.TotalTime
 			slowestTest = result.TestName
 		}
-		
+
 		if result.ServerDatasetSize > maxDataset {
 			maxDataset = result.ServerDatasetSize
 		}
 	}
-	
+
 	if successCount > 0 {
 		summary.AverageAccuracy = totalAccuracy / float64(successCount)
 		summary.AverageThroughput = totalThroughput / float64(successCount)
 	}
-	
+
 	// Calculate RAM averages
 	if ramRecordCount > 0 {
 		summary.AverageRAMPerServerRecord_MB = totalRAMPerServerRecord / float64(ramRecordCount)
 		summary.AverageRAMPerClientRecord_MB = totalRAMPerClientRecord / float64(ramRecordCount)
 	}
 	summary.PeakRAMUsed_MB = peakRAM
-	
+
 	// Calculate RAM scaling factor (MB per server record)
 	// This shows if RAM usage is linear with dataset size
 	if len(results) >= 2 && results[0].Success && results[len(results)-1].Success {
@@ -670,20 +843,20 @@ func generateSummary(results []TestResult) Summary {
 			summary.RAMScalingFactor = ramDiff / sizeDiff
 		}
 	}
-	
+
 	summary.TotalExecutionTime = totalExecTime.String()
 	summary.FastestTest = fmt.Sprintf("%s (%v)", fastestTest, fastestTime)
 	summary.SlowestTest = fmt.Sprintf("%s (%v)", slowestTest, slowestTime)
 	summary.LargestDatasetTested = maxDataset
-	
+
 	// Calculate scalability score (0-100)
 	// Based on: throughput, accuracy, and ability to handle large datasets
-	baseScore := (summary.AverageThroughput / 100.0) * 30 // Max 30 points for throughput
-	accuracyScore := (summary.AverageAccuracy / 100.0) * 40 // Max 40 points for accuracy
+	baseScore := (summary.AverageThroughput / 100.0) * 30        // Max 30 points for throughput
+	accuracyScore := (summary.AverageAccuracy / 100.0) * 40      // Max 40 points for accuracy
 	scaleScore := float64(min(maxDataset, 20000)) / 20000.0 * 30 // Max 30 points for scale
-	
+
 	summary.ScalabilityScore = minFloat(baseScore+accuracyScore+scaleScore, 100.0)
-	
+
 	return summary
 }
 
@@ -713,7 +886,334 @@ func saveJSONReport(filepath string, report ScalabilityReport) error {
 	return encoder.Encode(report)
 }
 
-func generateHTMLReport(htmlPath, jsonPath string) error {
+// historyIndexFileName is written to resultsDir alongside every run's JSON
+// report, listing every run found there - see writeHistoryIndex.
+const historyIndexFileName = "history_index.json"
+
+// writeHistoryIndex scans resultsDir for every scalability_test_*.json
+// report (including the one just written) and records them, oldest first,
+// as historyIndexFileName, so the HTML report can fetch the full run
+// history for regression detection instead of only the single run it was
+// generated alongside.
+func writeHistoryIndex(resultsDir string) error {
+	matches, err := filepath.Glob(filepath.Join(resultsDir, "scalability_test_*.json"))
+	if err != nil {
+		return fmt.Errorf("scalability_tests: glob history reports: %w", err)
+	}
+	sort.Strings(matches)
+
+	index := HistoryIndex{Runs: make([]HistoryRunRef, 0, len(matches))}
+	for _, m := range matches {
+		base := filepath.Base(m)
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(base, "scalability_test_"), ".json")
+		index.Runs = append(index.Runs, HistoryRunRef{Timestamp: timestamp, JSONFile: base})
+	}
+
+	file, err := os.Create(filepath.Join(resultsDir, historyIndexFileName))
+	if err != nil {
+		return fmt.Errorf("scalability_tests: create %s: %w", historyIndexFileName, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(index)
+}
+
+// reportI18NDictionary is the JS object literal backing the report's
+// language switcher (I18N in the embedded script): every label the report
+// renders, translated into English, Chinese, Spanish and French, so
+// setLanguage can relocalize an already-loaded report without re-fetching
+// anything.
+const reportI18NDictionary = `{
+    en: {
+        appTitle: '🔐 LE-PSI Scalability Report',
+        appSubtitle: 'Private Set Intersection - Performance Analysis',
+        testResultsHeading: '📊 Test Results',
+        performanceAnalysisHeading: '⚡ Performance Analysis',
+        historyHeading: '📈 Historical Trends & Regression Detection',
+        columnsBtn: 'Columns',
+        exportCsv: 'Export CSV',
+        exportJson: 'Export JSON',
+        reportGenerated: 'Report generated: ',
+        card_totalTests: 'Total Tests',
+        card_successRate: 'Success Rate',
+        card_totalMatches: 'Total Matches',
+        card_avgAccuracy: 'Avg Accuracy',
+        card_avgThroughput: 'Avg Throughput',
+        card_peakRam: 'Peak RAM',
+        card_ramPerRecord: 'RAM/Record',
+        card_scalabilityScore: 'Scalability Score',
+        col_testName: 'Test',
+        col_status: 'Status',
+        col_serverSize: 'Server Size',
+        col_clientSize: 'Client Size',
+        col_matches: 'Matches',
+        col_accuracy: 'Accuracy %',
+        col_totalTime: 'Total Time (ms)',
+        col_throughput: 'Throughput (ops/s)',
+        col_peakRam: 'Peak RAM (MB)',
+        col_ramPerRecord: 'RAM/Record (MB)',
+        statusSuccess: 'Success',
+        statusFailed: 'Failed',
+        filterPlaceholder: 'filter',
+        filterRangePlaceholder: 'min-max',
+        metric_datasetSize: 'Dataset Size',
+        metric_matchesFound: 'Matches Found',
+        metric_accuracy: 'Accuracy',
+        metric_totalTime: 'Total Time',
+        metric_throughput: 'Throughput',
+        metric_peakRam: 'Peak RAM',
+        metric_serverInitRam: 'Server Init RAM',
+        metric_ramPerServerRecord: 'RAM/Server Record',
+        goRuntimeHeading: '🔧 Go Runtime Performance',
+        metric_heapMemory: 'Heap Memory',
+        metric_systemMemory: 'System Memory',
+        metric_goroutines: 'Goroutines',
+        metric_gcRuns: 'GC Runs',
+        metric_gcCpuPct: 'GC CPU %',
+        metric_liveObjects: 'Live Objects',
+        metric_cpusUsed: 'CPUs Used',
+        metric_lastGcPause: 'Last GC Pause',
+        metric_gcPauseP99: 'GC Pause p99',
+        metric_schedLatencyP99: 'Sched Latency p99',
+        metric_heapGoal: 'Heap Goal',
+        heapVsGoalHeading: '📈 Heap In-Use vs. GC Goal',
+        legend_heapInUse: 'heap in use',
+        legend_gcGoal: 'GC goal',
+        legend_gcEvent: 'GC event',
+        gcPauseDistHeading: '📊 GC Pause Distribution (ms)',
+        gcPauseDistCaption: 'bucketed by pause duration; hover a bar for its range and count',
+        perf_largestDataset: 'Largest Dataset',
+        perf_totalDataProcessed: 'Total Data Processed',
+        perf_fastestTest: 'Fastest Test',
+        perf_slowestTest: 'Slowest Test',
+        historyNoData: 'No test ran in more than one prior run.',
+        regressionDetected: 'regression detected',
+        regressionBadge: 'regression',
+        hist_totalTime: 'Total Time (ms)',
+        hist_peakRam: 'Peak RAM (MB)',
+        hist_throughput: 'Throughput (ops/s)',
+        hist_accuracy: 'Accuracy (%)',
+    },
+    zh: {
+        appTitle: '🔐 LE-PSI 可扩展性报告',
+        appSubtitle: '隐私集合求交 - 性能分析',
+        testResultsHeading: '📊 测试结果',
+        performanceAnalysisHeading: '⚡ 性能分析',
+        historyHeading: '📈 历史趋势与回归检测',
+        columnsBtn: '列',
+        exportCsv: '导出 CSV',
+        exportJson: '导出 JSON',
+        reportGenerated: '报告生成时间：',
+        card_totalTests: '测试总数',
+        card_successRate: '成功率',
+        card_totalMatches: '匹配总数',
+        card_avgAccuracy: '平均准确率',
+        card_avgThroughput: '平均吞吐量',
+        card_peakRam: '峰值内存',
+        card_ramPerRecord: '每条记录内存',
+        card_scalabilityScore: '可扩展性得分',
+        col_testName: '测试',
+        col_status: '状态',
+        col_serverSize: '服务端规模',
+        col_clientSize: '客户端规模',
+        col_matches: '匹配数',
+        col_accuracy: '准确率 %',
+        col_totalTime: '总耗时 (ms)',
+        col_throughput: '吞吐量 (ops/s)',
+        col_peakRam: '峰值内存 (MB)',
+        col_ramPerRecord: '每条记录内存 (MB)',
+        statusSuccess: '成功',
+        statusFailed: '失败',
+        filterPlaceholder: '筛选',
+        filterRangePlaceholder: '最小-最大',
+        metric_datasetSize: '数据集规模',
+        metric_matchesFound: '找到的匹配',
+        metric_accuracy: '准确率',
+        metric_totalTime: '总耗时',
+        metric_throughput: '吞吐量',
+        metric_peakRam: '峰值内存',
+        metric_serverInitRam: '服务端初始化内存',
+        metric_ramPerServerRecord: '每条服务端记录内存',
+        goRuntimeHeading: '🔧 Go 运行时性能',
+        metric_heapMemory: '堆内存',
+        metric_systemMemory: '系统内存',
+        metric_goroutines: 'Goroutine 数',
+        metric_gcRuns: 'GC 次数',
+        metric_gcCpuPct: 'GC CPU 占比',
+        metric_liveObjects: '存活对象',
+        metric_cpusUsed: '使用的 CPU',
+        metric_lastGcPause: '最近一次 GC 暂停',
+        metric_gcPauseP99: 'GC 暂停 p99',
+        metric_schedLatencyP99: '调度延迟 p99',
+        metric_heapGoal: '堆目标',
+        heapVsGoalHeading: '📈 堆内存使用 vs. GC 目标',
+        legend_heapInUse: '堆内存使用',
+        legend_gcGoal: 'GC 目标',
+        legend_gcEvent: 'GC 事件',
+        gcPauseDistHeading: '📊 GC 暂停分布 (ms)',
+        gcPauseDistCaption: '按暂停时长分桶；将鼠标悬停在柱状图上查看范围和计数',
+        perf_largestDataset: '最大数据集',
+        perf_totalDataProcessed: '处理的数据总量',
+        perf_fastestTest: '最快的测试',
+        perf_slowestTest: '最慢的测试',
+        historyNoData: '没有测试在多于一次历史运行中出现。',
+        regressionDetected: '检测到回归',
+        regressionBadge: '回归',
+        hist_totalTime: '总耗时 (ms)',
+        hist_peakRam: '峰值内存 (MB)',
+        hist_throughput: '吞吐量 (ops/s)',
+        hist_accuracy: '准确率 (%)',
+    },
+    es: {
+        appTitle: '🔐 Informe de Escalabilidad LE-PSI',
+        appSubtitle: 'Intersección de Conjuntos Privados - Análisis de Rendimiento',
+        testResultsHeading: '📊 Resultados de las Pruebas',
+        performanceAnalysisHeading: '⚡ Análisis de Rendimiento',
+        historyHeading: '📈 Tendencias Históricas y Detección de Regresiones',
+        columnsBtn: 'Columnas',
+        exportCsv: 'Exportar CSV',
+        exportJson: 'Exportar JSON',
+        reportGenerated: 'Informe generado: ',
+        card_totalTests: 'Pruebas Totales',
+        card_successRate: 'Tasa de Éxito',
+        card_totalMatches: 'Coincidencias Totales',
+        card_avgAccuracy: 'Precisión Media',
+        card_avgThroughput: 'Rendimiento Medio',
+        card_peakRam: 'RAM Máxima',
+        card_ramPerRecord: 'RAM/Registro',
+        card_scalabilityScore: 'Puntuación de Escalabilidad',
+        col_testName: 'Prueba',
+        col_status: 'Estado',
+        col_serverSize: 'Tamaño del Servidor',
+        col_clientSize: 'Tamaño del Cliente',
+        col_matches: 'Coincidencias',
+        col_accuracy: 'Precisión %',
+        col_totalTime: 'Tiempo Total (ms)',
+        col_throughput: 'Rendimiento (ops/s)',
+        col_peakRam: 'RAM Máxima (MB)',
+        col_ramPerRecord: 'RAM/Registro (MB)',
+        statusSuccess: 'Éxito',
+        statusFailed: 'Fallido',
+        filterPlaceholder: 'filtro',
+        filterRangePlaceholder: 'min-max',
+        metric_datasetSize: 'Tamaño del Conjunto de Datos',
+        metric_matchesFound: 'Coincidencias Encontradas',
+        metric_accuracy: 'Precisión',
+        metric_totalTime: 'Tiempo Total',
+        metric_throughput: 'Rendimiento',
+        metric_peakRam: 'RAM Máxima',
+        metric_serverInitRam: 'RAM de Inicialización del Servidor',
+        metric_ramPerServerRecord: 'RAM/Registro del Servidor',
+        goRuntimeHeading: '🔧 Rendimiento del Runtime de Go',
+        metric_heapMemory: 'Memoria Heap',
+        metric_systemMemory: 'Memoria del Sistema',
+        metric_goroutines: 'Goroutines',
+        metric_gcRuns: 'Ejecuciones de GC',
+        metric_gcCpuPct: 'CPU de GC %',
+        metric_liveObjects: 'Objetos Vivos',
+        metric_cpusUsed: 'CPUs Usadas',
+        metric_lastGcPause: 'Última Pausa de GC',
+        metric_gcPauseP99: 'Pausa de GC p99',
+        metric_schedLatencyP99: 'Latencia de Planificación p99',
+        metric_heapGoal: 'Objetivo de Heap',
+        heapVsGoalHeading: '📈 Heap en Uso vs. Objetivo de GC',
+        legend_heapInUse: 'heap en uso',
+        legend_gcGoal: 'objetivo de GC',
+        legend_gcEvent: 'evento de GC',
+        gcPauseDistHeading: '📊 Distribución de Pausas de GC (ms)',
+        gcPauseDistCaption: 'agrupado por duración de pausa; pase el cursor sobre una barra para ver su rango y conteo',
+        perf_largestDataset: 'Conjunto de Datos Más Grande',
+        perf_totalDataProcessed: 'Total de Datos Procesados',
+        perf_fastestTest: 'Prueba Más Rápida',
+        perf_slowestTest: 'Prueba Más Lenta',
+        historyNoData: 'Ninguna prueba se ejecutó en más de una corrida anterior.',
+        regressionDetected: 'regresión detectada',
+        regressionBadge: 'regresión',
+        hist_totalTime: 'Tiempo Total (ms)',
+        hist_peakRam: 'RAM Máxima (MB)',
+        hist_throughput: 'Rendimiento (ops/s)',
+        hist_accuracy: 'Precisión (%)',
+    },
+    fr: {
+        appTitle: '🔐 Rapport de Scalabilité LE-PSI',
+        appSubtitle: "Intersection d'Ensembles Privés - Analyse des Performances",
+        testResultsHeading: '📊 Résultats des Tests',
+        performanceAnalysisHeading: '⚡ Analyse des Performances',
+        historyHeading: '📈 Tendances Historiques et Détection de Régressions',
+        columnsBtn: 'Colonnes',
+        exportCsv: 'Exporter en CSV',
+        exportJson: 'Exporter en JSON',
+        reportGenerated: 'Rapport généré : ',
+        card_totalTests: 'Tests Totaux',
+        card_successRate: 'Taux de Réussite',
+        card_totalMatches: 'Correspondances Totales',
+        card_avgAccuracy: 'Précision Moyenne',
+        card_avgThroughput: 'Débit Moyen',
+        card_peakRam: 'RAM Maximale',
+        card_ramPerRecord: 'RAM/Enregistrement',
+        card_scalabilityScore: 'Score de Scalabilité',
+        col_testName: 'Test',
+        col_status: 'Statut',
+        col_serverSize: 'Taille Serveur',
+        col_clientSize: 'Taille Client',
+        col_matches: 'Correspondances',
+        col_accuracy: 'Précision %',
+        col_totalTime: 'Temps Total (ms)',
+        col_throughput: 'Débit (ops/s)',
+        col_peakRam: 'RAM Maximale (MB)',
+        col_ramPerRecord: 'RAM/Enregistrement (MB)',
+        statusSuccess: 'Réussi',
+        statusFailed: 'Échoué',
+        filterPlaceholder: 'filtre',
+        filterRangePlaceholder: 'min-max',
+        metric_datasetSize: 'Taille du Jeu de Données',
+        metric_matchesFound: 'Correspondances Trouvées',
+        metric_accuracy: 'Précision',
+        metric_totalTime: 'Temps Total',
+        metric_throughput: 'Débit',
+        metric_peakRam: 'RAM Maximale',
+        metric_serverInitRam: "RAM d'Initialisation du Serveur",
+        metric_ramPerServerRecord: 'RAM/Enregistrement Serveur',
+        goRuntimeHeading: '🔧 Performance du Runtime Go',
+        metric_heapMemory: 'Mémoire Heap',
+        metric_systemMemory: 'Mémoire Système',
+        metric_goroutines: 'Goroutines',
+        metric_gcRuns: 'Exécutions GC',
+        metric_gcCpuPct: 'CPU GC %',
+        metric_liveObjects: 'Objets Vivants',
+        metric_cpusUsed: 'CPU Utilisés',
+        metric_lastGcPause: 'Dernière Pause GC',
+        metric_gcPauseP99: 'Pause GC p99',
+        metric_schedLatencyP99: "Latence d'Ordonnancement p99",
+        metric_heapGoal: 'Objectif Heap',
+        heapVsGoalHeading: '📈 Heap Utilisé vs. Objectif GC',
+        legend_heapInUse: 'heap utilisé',
+        legend_gcGoal: 'objectif GC',
+        legend_gcEvent: 'événement GC',
+        gcPauseDistHeading: '📊 Distribution des Pauses GC (ms)',
+        gcPauseDistCaption: 'regroupé par durée de pause ; survolez une barre pour voir sa plage et son nombre',
+        perf_largestDataset: 'Jeu de Données le Plus Grand',
+        perf_totalDataProcessed: 'Total des Données Traitées',
+        perf_fastestTest: 'Test le Plus Rapide',
+        perf_slowestTest: 'Test le Plus Lent',
+        historyNoData: "Aucun test n'a été exécuté plus d'une fois.",
+        regressionDetected: 'régression détectée',
+        regressionBadge: 'régression',
+        hist_totalTime: 'Temps Total (ms)',
+        hist_peakRam: 'RAM Maximale (MB)',
+        hist_throughput: 'Débit (ops/s)',
+        hist_accuracy: 'Précision (%)',
+    },
+}`
+
+// generateHTMLReport writes htmlPath as a self-contained report page whose
+// default UI language is defaultLang (from -report-lang). Every supported
+// language's dictionary (see I18N in the embedded script) ships in the same
+// page, so a reader can switch languages via the header <select> without
+// the report being regenerated.
+func generateHTMLReport(htmlPath, defaultLang string) error {
 	htmlContent := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -812,53 +1312,98 @@ func generateHTMLReport(htmlPath, jsonPath string) error {
             border-bottom: 2px solid #667eea;
         }
         
-        .test-results {
-            display: grid;
-            gap: 1rem;
+        .table-toolbar {
+            display: flex;
+            justify-content: flex-end;
+            gap: 0.5rem;
+            margin-bottom: 0.75rem;
+            position: relative;
         }
-        
-        .test-card {
+
+        .table-toolbar button {
+            padding: 0.4rem 0.9rem;
+            border: 1px solid #667eea;
+            border-radius: 4px;
             background: white;
-            border-radius: 6px;
-            padding: 1.5rem;
-            border-left: 4px solid #667eea;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.05);
+            color: #667eea;
+            font-size: 0.85rem;
+            cursor: pointer;
         }
-        
-        .test-card.failed {
-            border-left-color: #e74c3c;
+
+        .table-toolbar button:hover {
+            background: #667eea;
+            color: white;
         }
-        
-        .test-header {
-            display: flex;
-            justify-content: space-between;
-            align-items: center;
-            margin-bottom: 1rem;
+
+        .column-menu {
+            position: absolute;
+            top: 100%;
+            right: 0;
+            background: white;
+            border: 1px solid #e9ecef;
+            border-radius: 4px;
+            box-shadow: 0 4px 10px rgba(0,0,0,0.15);
+            padding: 0.5rem 0.75rem;
+            z-index: 10;
         }
-        
-        .test-name {
-            font-size: 1.2rem;
-            font-weight: 600;
-            color: #2c3e50;
+
+        .column-menu label {
+            display: block;
+            font-size: 0.85rem;
+            white-space: nowrap;
+            padding: 0.15rem 0;
         }
-        
-        .test-status {
-            padding: 0.25rem 0.75rem;
-            border-radius: 20px;
+
+        .results-table-wrap {
+            overflow-x: auto;
+        }
+
+        table.results-table {
+            width: 100%;
+            border-collapse: collapse;
+            background: white;
             font-size: 0.85rem;
-            font-weight: 600;
         }
-        
-        .test-status.success {
-            background: #d4edda;
-            color: #155724;
+
+        .results-table th, .results-table td {
+            padding: 0.5rem 0.75rem;
+            text-align: left;
+            border-bottom: 1px solid #e9ecef;
+            white-space: nowrap;
         }
-        
-        .test-status.failed {
-            background: #f8d7da;
-            color: #721c24;
+
+        .results-table thead th {
+            cursor: pointer;
+            color: #2c3e50;
+            background: #f1f3f8;
+            user-select: none;
         }
-        
+
+        .results-table thead th.sorted-asc::after { content: ' \25B2'; }
+        .results-table thead th.sorted-desc::after { content: ' \25BC'; }
+
+        .results-table tbody tr:hover {
+            background: #f8f9fa;
+            cursor: pointer;
+        }
+
+        .results-table tbody tr.failed {
+            color: #e74c3c;
+        }
+
+        .results-table .filter-row input {
+            width: 100%;
+            padding: 0.25rem 0.4rem;
+            font-size: 0.8rem;
+            border: 1px solid #e9ecef;
+            border-radius: 3px;
+        }
+
+        .results-table .detail-row td {
+            background: #fafbfc;
+            white-space: normal;
+        }
+
         .test-metrics {
             display: grid;
             grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
@@ -916,8 +1461,14 @@ func generateHTMLReport(htmlPath, jsonPath string) error {
 <body>
     <div class="container">
         <div class="header">
-            <h1>üîê LE-PSI Scalability Report</h1>
-            <p>Private Set Intersection - Performance Analysis</p>
+            <h1 id="pageTitle">üîê LE-PSI Scalability Report</h1>
+            <p id="pageSubtitle">Private Set Intersection - Performance Analysis</p>
+            <select id="langSelect" onchange="setLanguage(this.value)" style="margin-top: 0.75rem; padding: 0.3rem 0.6rem; border-radius: 4px; border: none;">
+                <option value="en">English</option>
+                <option value="zh">中文</option>
+                <option value="es">Español</option>
+                <option value="fr">Français</option>
+            </select>
         </div>
 
         <div class="content">
@@ -931,56 +1482,142 @@ func generateHTMLReport(htmlPath, jsonPath string) error {
 
                 <!-- Detailed Results -->
                 <div class="section">
-                    <h2>üìä Test Results</h2>
-                    <div class="test-results" id="testResults"></div>
+                <div class="section">
+                    <h2 id="testResultsHeading">üìä Test Results</h2>
+                    <div class="table-toolbar">
+                        <button id="columnsBtn" onclick="toggleColumnMenu()">Columns</button>
+                        <div id="columnMenu" class="column-menu" style="display: none;"></div>
+                        <button id="exportCsvBtn" onclick="exportResults('csv')">Export CSV</button>
+                        <button id="exportJsonBtn" onclick="exportResults('json')">Export JSON</button>
+                    </div>
+                    <div class="results-table-wrap">
+                        <table class="results-table" id="resultsTable"></table>
+                    </div>
                 </div>
 
                 <!-- Performance Analysis -->
                 <div class="section">
-                    <h2>‚ö° Performance Analysis</h2>
+                    <h2 id="performanceAnalysisHeading">‚ö° Performance Analysis</h2>
                     <div id="performanceAnalysis"></div>
                 </div>
 
+                <!-- Historical Trends, only shown when resultsDir holds more than one run -->
+                <div class="section" id="historySection" style="display: none;">
+                    <h2 id="historyHeading">üìà Historical Trends &amp; Regression Detection</h2>
+                    <div id="historyTrends"></div>
+                </div>
+
                 <div class="timestamp" id="timestamp"></div>
             </div>
         </div>
     </div>
 
     <script>
+        // I18N holds every label this report renders, in every supported
+        // language. defaultReportLang below only picks the language shown
+        // before a cookie or explicit selection exists - switching via
+        // #langSelect re-renders from this table with no re-fetch, per
+        // request chunk4-5 ("re-localized in the browser without
+        // regenerating").
+        const I18N = ` + reportI18NDictionary + `;
+
+        const defaultReportLang = '` + defaultLang + `';
+
+        function readLangCookie() {
+            const match = document.cookie.match(/(?:^|; )report_lang=([^;]+)/);
+            return match ? decodeURIComponent(match[1]) : null;
+        }
+
+        let currentLang = (function () {
+            const cookieLang = readLangCookie();
+            if (cookieLang && I18N[cookieLang]) return cookieLang;
+            return I18N[defaultReportLang] ? defaultReportLang : 'en';
+        })();
+
+        // t looks up key in the active language, falling back to English
+        // and then the key itself so a missing translation never renders
+        // as "undefined".
+        function t(key) {
+            return (I18N[currentLang] && I18N[currentLang][key]) || I18N.en[key] || key;
+        }
+
+        // lastReport/lastRuns cache the most recently fetched data so
+        // setLanguage can re-render in place instead of re-fetching.
+        let lastReport = null;
+        let lastRuns = null;
+
+        function applyStaticLabels() {
+            document.getElementById('pageTitle').textContent = t('appTitle');
+            document.getElementById('pageSubtitle').textContent = t('appSubtitle');
+            document.getElementById('testResultsHeading').textContent = t('testResultsHeading');
+            document.getElementById('columnsBtn').textContent = t('columnsBtn');
+            document.getElementById('exportCsvBtn').textContent = t('exportCsv');
+            document.getElementById('exportJsonBtn').textContent = t('exportJson');
+            document.getElementById('performanceAnalysisHeading').textContent = t('performanceAnalysisHeading');
+            document.getElementById('historyHeading').textContent = t('historyHeading');
+        }
+
+        function setLanguage(lang) {
+            currentLang = I18N[lang] ? lang : 'en';
+            document.cookie = 'report_lang=' + encodeURIComponent(currentLang) + '; path=/; max-age=31536000';
+            document.getElementById('langSelect').value = currentLang;
+            applyStaticLabels();
+            renderColumnMenu();
+            if (lastReport) {
+                renderReport(lastReport);
+            }
+            if (lastRuns && lastRuns.length > 1) {
+                renderHistoryTrends(lastRuns);
+            }
+        }
+
         async function loadData() {
             try {
-                const jsonFile = '` + filepath.Base(jsonPath) + `';
-                const response = await fetch(jsonFile);
-                const data = await response.json();
-                renderReport(data);
+                const indexResp = await fetch('` + historyIndexFileName + `');
+                const index = await indexResp.json();
+                const runs = [];
+                for (const ref of (index.runs || [])) {
+                    const resp = await fetch(ref.json_file);
+                    runs.push({ timestamp: ref.timestamp, report: await resp.json() });
+                }
+                if (runs.length === 0) {
+                    throw new Error('no runs in history index');
+                }
+
+                lastRuns = runs;
+                renderReport(runs[runs.length - 1].report);
+                if (runs.length > 1) {
+                    renderHistoryTrends(runs);
+                }
             } catch (error) {
-                document.getElementById('loading').innerHTML = 
+                document.getElementById('loading').innerHTML =
                     '<div style="color: #e74c3c;">Error loading test results</div>';
             }
         }
 
         function renderReport(data) {
+            lastReport = data;
             document.getElementById('loading').style.display = 'none';
             document.getElementById('report').style.display = 'block';
-            
+
             renderSummaryCards(data);
             renderTestResults(data);
             renderPerformanceAnalysis(data);
-            
-            document.getElementById('timestamp').innerHTML = 
-                'Report generated: ' + data.timestamp;
+
+            document.getElementById('timestamp').innerHTML =
+                t('reportGenerated') + data.timestamp;
         }
 
         function renderSummaryCards(data) {
             const cards = [
-                { label: 'Total Tests', value: data.total_tests },
-                { label: 'Success Rate', value: ((data.successful_tests / data.total_tests) * 100).toFixed(1) + '%' },
-                { label: 'Total Matches', value: data.summary.total_matches_found.toLocaleString() },
-                { label: 'Avg Accuracy', value: data.summary.average_accuracy.toFixed(2) + '%' },
-                { label: 'Avg Throughput', value: data.summary.average_throughput_ops_per_sec.toFixed(1) + ' ops/s' },
-                { label: 'Peak RAM', value: data.summary.peak_ram_used_mb.toFixed(1) + ' MB' },
-                { label: 'RAM/Record', value: data.summary.avg_ram_per_server_record_mb.toFixed(2) + ' MB' },
-                { label: 'Scalability Score', value: data.summary.scalability_score.toFixed(1) + '/100' }
+                { label: t('card_totalTests'), value: data.total_tests },
+                { label: t('card_successRate'), value: ((data.successful_tests / data.total_tests) * 100).toFixed(1) + '%' },
+                { label: t('card_totalMatches'), value: data.summary.total_matches_found.toLocaleString() },
+                { label: t('card_avgAccuracy'), value: data.summary.average_accuracy.toFixed(2) + '%' },
+                { label: t('card_avgThroughput'), value: data.summary.average_throughput_ops_per_sec.toFixed(1) + ' ops/s' },
+                { label: t('card_peakRam'), value: data.summary.peak_ram_used_mb.toFixed(1) + ' MB' },
+                { label: t('card_ramPerRecord'), value: data.summary.avg_ram_per_server_record_mb.toFixed(2) + ' MB' },
+                { label: t('card_scalabilityScore'), value: data.summary.scalability_score.toFixed(1) + '/100' }
             ];
 
             const html = cards.map(card => 
@@ -993,119 +1630,423 @@ func generateHTMLReport(htmlPath, jsonPath string) error {
             document.getElementById('summaryCards').innerHTML = html;
         }
 
+        // RESULT_COLUMNS defines every column the results table can show:
+        // how to pull its raw value out of a TestResult (get), how to
+        // compare two raw values when sorting (type), and how to format
+        // the raw value for display/export (format).
+        // labelKey names the I18N entry for a column's header/menu text
+        // instead of baking in an English label, since RESULT_COLUMNS is
+        // built once but the active language can change afterward via
+        // setLanguage - see columnLabel.
+        const RESULT_COLUMNS = [
+            { key: 'test_name', labelKey: 'col_testName', type: 'string', get: row => row.test_name, format: v => v },
+            { key: 'status', labelKey: 'col_status', type: 'string', get: row => row.success, format: v => v ? t('statusSuccess') : t('statusFailed') },
+            { key: 'server_dataset_size', labelKey: 'col_serverSize', type: 'number', get: row => row.server_dataset_size, format: v => v.toLocaleString() },
+            { key: 'client_dataset_size', labelKey: 'col_clientSize', type: 'number', get: row => row.client_dataset_size, format: v => v.toLocaleString() },
+            { key: 'matches_found', labelKey: 'col_matches', type: 'number', get: row => row.matches_found, format: v => v.toLocaleString() },
+            { key: 'accuracy', labelKey: 'col_accuracy', type: 'number', get: row => row.accuracy, format: v => v.toFixed(2) },
+            { key: 'total_time_ms', labelKey: 'col_totalTime', type: 'number', get: row => row.total_time_ns / 1000000, format: v => v.toFixed(0) },
+            { key: 'throughput_ops_per_sec', labelKey: 'col_throughput', type: 'number', get: row => row.throughput_ops_per_sec, format: v => v.toFixed(1) },
+            { key: 'peak_ram_mb', labelKey: 'col_peakRam', type: 'number', get: row => row.ram_analysis.peak_ram_mb, format: v => v.toFixed(1) },
+            { key: 'ram_per_server_record_mb', labelKey: 'col_ramPerRecord', type: 'number', get: row => row.ram_analysis.ram_per_server_record_mb, format: v => v.toFixed(3) },
+        ];
+
+        // columnLabel resolves a column's current-language header text.
+        function columnLabel(col) {
+            return t(col.labelKey);
+        }
+
+        // tableState holds the results table's sort/filter/visibility/
+        // expansion state across re-renders, since renderResultsTable is
+        // called again on every sort click, filter keystroke and column
+        // toggle.
+        const tableState = {
+            tests: [],
+            sortKey: null,
+            sortDir: 1,
+            filters: {},
+            hiddenColumns: new Set(),
+            expanded: new Set(),
+        };
+
         function renderTestResults(data) {
-            const html = data.test_results.map(test => {
-                const statusClass = test.success ? 'success' : 'failed';
-                const status = test.success ? '‚úì Success' : '‚úó Failed';
-                
-                return '<div class="test-card ' + (test.success ? '' : 'failed') + '">' +
-                    '<div class="test-header">' +
-                        '<div class="test-name">' + test.test_name + '</div>' +
-                        '<div class="test-status ' + statusClass + '">' + status + '</div>' +
-                    '</div>' +
-                    (test.success ? renderTestMetrics(test) : 
-                        '<div style="color: #e74c3c;">' + test.error_message + '</div>') +
-                '</div>';
+            tableState.tests = data.test_results;
+            renderColumnMenu();
+            renderResultsTable();
+        }
+
+        // compareValues implements the comparators a click-to-sort column
+        // needs: plain numeric compare, locale string compare, dotted
+        // version-string compare (e.g. "1.10" > "1.9"), and hex-string
+        // compare (numeric value of the hex digits) - covering every column
+        // type this report or a future one is likely to add.
+        function compareValues(a, b, type) {
+            switch (type) {
+                case 'number':
+                    return a - b;
+                case 'version': {
+                    const pa = String(a).split('.').map(Number);
+                    const pb = String(b).split('.').map(Number);
+                    for (let i = 0; i < Math.max(pa.length, pb.length); i++) {
+                        const diff = (pa[i] || 0) - (pb[i] || 0);
+                        if (diff !== 0) return diff;
+                    }
+                    return 0;
+                }
+                case 'hex':
+                    return parseInt(a, 16) - parseInt(b, 16);
+                default:
+                    return String(a).localeCompare(String(b));
+            }
+        }
+
+        // matchesFilter applies one column's filter text to a raw value: a
+        // "min-max" range for numeric columns, otherwise a case-insensitive
+        // substring match against the formatted display value.
+        function matchesFilter(col, rawValue, filterText) {
+            if (!filterText) return true;
+            if (col.type === 'number') {
+                const range = filterText.match(/^(-?[\d.]*)-(-?[\d.]*)$/);
+                if (range) {
+                    const min = range[1] === '' ? -Infinity : parseFloat(range[1]);
+                    const max = range[2] === '' ? Infinity : parseFloat(range[2]);
+                    return rawValue >= min && rawValue <= max;
+                }
+            }
+            return col.format(rawValue).toLowerCase().includes(filterText.toLowerCase());
+        }
+
+        function filteredSortedTests() {
+            let rows = tableState.tests.filter(test =>
+                RESULT_COLUMNS.every(col => matchesFilter(col, col.get(test), tableState.filters[col.key]))
+            );
+            if (tableState.sortKey) {
+                const col = RESULT_COLUMNS.find(c => c.key === tableState.sortKey);
+                rows = rows.slice().sort((a, b) =>
+                    tableState.sortDir * compareValues(col.get(a), col.get(b), col.type));
+            }
+            return rows;
+        }
+
+        function visibleColumns() {
+            return RESULT_COLUMNS.filter(col => !tableState.hiddenColumns.has(col.key));
+        }
+
+        function toggleSort(key) {
+            if (tableState.sortKey === key) {
+                tableState.sortDir *= -1;
+            } else {
+                tableState.sortKey = key;
+                tableState.sortDir = 1;
+            }
+            renderResultsTable();
+        }
+
+        function setFilter(key, value) {
+            tableState.filters[key] = value;
+            renderResultsTable();
+        }
+
+        function toggleRowExpanded(index) {
+            if (tableState.expanded.has(index)) {
+                tableState.expanded.delete(index);
+            } else {
+                tableState.expanded.add(index);
+            }
+            renderResultsTable();
+        }
+
+        function toggleColumn(key) {
+            if (tableState.hiddenColumns.has(key)) {
+                tableState.hiddenColumns.delete(key);
+            } else {
+                tableState.hiddenColumns.add(key);
+            }
+            renderColumnMenu();
+            renderResultsTable();
+        }
+
+        function toggleColumnMenu() {
+            const menu = document.getElementById('columnMenu');
+            menu.style.display = menu.style.display === 'none' ? 'block' : 'none';
+        }
+
+        function renderColumnMenu() {
+            document.getElementById('columnMenu').innerHTML = RESULT_COLUMNS.map(col =>
+                '<label><input type="checkbox" ' + (tableState.hiddenColumns.has(col.key) ? '' : 'checked') +
+                    ' onchange="toggleColumn(\'' + col.key + '\')"> ' + columnLabel(col) + '</label>'
+            ).join('');
+        }
+
+        function renderResultsTable() {
+            const cols = visibleColumns();
+            const rows = filteredSortedTests();
+
+            const headerCells = cols.map(col => {
+                const sortClass = tableState.sortKey === col.key ?
+                    (tableState.sortDir === 1 ? ' sorted-asc' : ' sorted-desc') : '';
+                return '<th class="' + sortClass.trim() + '" onclick="toggleSort(\'' + col.key + '\')">' + columnLabel(col) + '</th>';
             }).join('');
-            
-            document.getElementById('testResults').innerHTML = html;
+
+            const filterCells = cols.map(col =>
+                '<td><input type="text" placeholder="' + (col.type === 'number' ? t('filterRangePlaceholder') : t('filterPlaceholder')) +
+                    '" value="' + (tableState.filters[col.key] || '') +
+                    '" oninput="setFilter(\'' + col.key + '\', this.value)"></td>'
+            ).join('');
+
+            const bodyRows = rows.map((test, i) => {
+                const cells = cols.map(col => '<td>' + col.format(col.get(test)) + '</td>').join('');
+                let html = '<tr class="' + (test.success ? '' : 'failed') + '" onclick="toggleRowExpanded(' + i + ')">' + cells + '</tr>';
+                if (tableState.expanded.has(i)) {
+                    html += '<tr class="detail-row"><td colspan="' + cols.length + '">' +
+                        (test.success ? renderTestMetrics(test) :
+                            '<div style="color: #e74c3c;">' + test.error_message + '</div>') +
+                        '</td></tr>';
+                }
+                return html;
+            }).join('');
+
+            document.getElementById('resultsTable').innerHTML =
+                '<thead><tr>' + headerCells + '</tr><tr class="filter-row">' + filterCells + '</tr></thead>' +
+                '<tbody>' + bodyRows + '</tbody>';
+        }
+
+        // exportResults downloads the currently filtered/sorted/visible
+        // view as CSV or JSON, so a comparative sweep across dozens of
+        // rows can be pulled into a spreadsheet or another tool.
+        function exportResults(format) {
+            const cols = visibleColumns();
+            const rows = filteredSortedTests();
+            let blob, filename;
+
+            if (format === 'json') {
+                const data = rows.map(test => {
+                    const obj = {};
+                    cols.forEach(col => { obj[col.key] = col.get(test); });
+                    return obj;
+                });
+                blob = new Blob([JSON.stringify(data, null, 2)], { type: 'application/json' });
+                filename = 'scalability_results.json';
+            } else {
+                const escapeCSV = v => {
+                    const s = String(v);
+                    return /[",\n]/.test(s) ? '"' + s.replace(/"/g, '""') + '"' : s;
+                };
+                const lines = [cols.map(col => escapeCSV(columnLabel(col))).join(',')];
+                rows.forEach(test => {
+                    lines.push(cols.map(col => escapeCSV(col.format(col.get(test)))).join(','));
+                });
+                blob = new Blob([lines.join('\n')], { type: 'text/csv' });
+                filename = 'scalability_results.csv';
+            }
+
+            const url = URL.createObjectURL(blob);
+            const a = document.createElement('a');
+            a.href = url;
+            a.download = filename;
+            a.click();
+            URL.revokeObjectURL(url);
         }
 
         function renderTestMetrics(test) {
             let html = '<div class="test-metrics">' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Dataset Size</span>' +
+                    '<span class="metric-label">' + t('metric_datasetSize') + '</span>' +
                     '<span class="metric-value">' + test.server_dataset_size.toLocaleString() + ' / ' + 
                     test.client_dataset_size.toLocaleString() + '</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Matches Found</span>' +
+                    '<span class="metric-label">' + t('metric_matchesFound') + '</span>' +
                     '<span class="metric-value">' + test.matches_found + ' / ' + test.overlap_size + '</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Accuracy</span>' +
+                    '<span class="metric-label">' + t('metric_accuracy') + '</span>' +
                     '<span class="metric-value">' + test.accuracy.toFixed(2) + '%</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Total Time</span>' +
+                    '<span class="metric-label">' + t('metric_totalTime') + '</span>' +
                     '<span class="metric-value">' + (test.total_time_ns / 1000000).toFixed(0) + ' ms</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Throughput</span>' +
+                    '<span class="metric-label">' + t('metric_throughput') + '</span>' +
                     '<span class="metric-value">' + test.throughput_ops_per_sec.toFixed(1) + ' ops/s</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Peak RAM</span>' +
+                    '<span class="metric-label">' + t('metric_peakRam') + '</span>' +
                     '<span class="metric-value">' + test.ram_analysis.peak_ram_mb.toFixed(1) + ' MB</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Server Init RAM</span>' +
+                    '<span class="metric-label">' + t('metric_serverInitRam') + '</span>' +
                     '<span class="metric-value">' + test.ram_analysis.server_init_ram_delta_mb.toFixed(1) + ' MB</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">RAM/Server Record</span>' +
+                    '<span class="metric-label">' + t('metric_ramPerServerRecord') + '</span>' +
                     '<span class="metric-value">' + test.ram_analysis.ram_per_server_record_mb.toFixed(3) + ' MB</span>' +
                 '</div>' +
             '</div>';
             
             // Add Go Runtime Statistics if available
             if (test.go_runtime_stats) {
-                html += '<h4 style="margin-top: 20px; color: #2c3e50;">üîß Go Runtime Performance</h4>';
+                html += '<h4 style="margin-top: 20px; color: #2c3e50;">' + t('goRuntimeHeading') + '</h4>';
                 html += '<div class="test-metrics">' +
                     '<div class="metric">' +
-                        '<span class="metric-label">Heap Memory</span>' +
+                        '<span class="metric-label">' + t('metric_heapMemory') + '</span>' +
                         '<span class="metric-value">' + test.go_runtime_stats.heap_alloc_mb.toFixed(2) + ' MB</span>' +
                     '</div>' +
                     '<div class="metric">' +
-                        '<span class="metric-label">System Memory</span>' +
+                        '<span class="metric-label">' + t('metric_systemMemory') + '</span>' +
                         '<span class="metric-value">' + test.go_runtime_stats.system_memory_mb.toFixed(2) + ' MB</span>' +
                     '</div>' +
                     '<div class="metric">' +
-                        '<span class="metric-label">Goroutines</span>' +
+                        '<span class="metric-label">' + t('metric_goroutines') + '</span>' +
                         '<span class="metric-value">' + test.go_runtime_stats.num_goroutines + '</span>' +
                     '</div>' +
                     '<div class="metric">' +
-                        '<span class="metric-label">GC Runs</span>' +
+                        '<span class="metric-label">' + t('metric_gcRuns') + '</span>' +
                         '<span class="metric-value">' + test.go_runtime_stats.num_gc + '</span>' +
                     '</div>' +
                     '<div class="metric">' +
-                        '<span class="metric-label">GC CPU %</span>' +
+                        '<span class="metric-label">' + t('metric_gcCpuPct') + '</span>' +
                         '<span class="metric-value">' + test.go_runtime_stats.gc_cpu_percentage.toFixed(2) + '%</span>' +
                     '</div>' +
                     '<div class="metric">' +
-                        '<span class="metric-label">Live Objects</span>' +
+                        '<span class="metric-label">' + t('metric_liveObjects') + '</span>' +
                         '<span class="metric-value">' + test.go_runtime_stats.live_objects.toLocaleString() + '</span>' +
                     '</div>' +
                     '<div class="metric">' +
-                        '<span class="metric-label">CPUs Used</span>' +
+                        '<span class="metric-label">' + t('metric_cpusUsed') + '</span>' +
                         '<span class="metric-value">' + test.go_runtime_stats.gomaxprocs + ' / ' + test.go_runtime_stats.num_cpu + '</span>' +
                     '</div>' +
                     '<div class="metric">' +
-                        '<span class="metric-label">Last GC Pause</span>' +
+                        '<span class="metric-label">' + t('metric_lastGcPause') + '</span>' +
                         '<span class="metric-value">' + test.go_runtime_stats.last_gc_pause_ms.toFixed(2) + ' ms</span>' +
                     '</div>' +
+                    '<div class="metric">' +
+                        '<span class="metric-label">' + t('metric_gcPauseP99') + '</span>' +
+                        '<span class="metric-value">' + test.go_runtime_stats.gc_pause_p99_ms.toFixed(2) + ' ms</span>' +
+                    '</div>' +
+                    '<div class="metric">' +
+                        '<span class="metric-label">' + t('metric_schedLatencyP99') + '</span>' +
+                        '<span class="metric-value">' + test.go_runtime_stats.sched_latency_p99_ms.toFixed(2) + ' ms</span>' +
+                    '</div>' +
+                    '<div class="metric">' +
+                        '<span class="metric-label">' + t('metric_heapGoal') + '</span>' +
+                        '<span class="metric-value">' + test.go_runtime_stats.heap_goal_mb.toFixed(2) + ' MB</span>' +
+                    '</div>' +
                 '</div>';
+
+                if (test.runtime_timeline && test.runtime_timeline.length > 1) {
+                    html += renderRuntimeTimeline(test.runtime_timeline);
+                }
+
+                if (test.gc_pause_histogram && test.gc_pause_histogram.length > 0) {
+                    html += renderLatencyHistogram(test.gc_pause_histogram);
+                }
             }
-            
+
             return html;
         }
 
+        // gcEventMarkers finds every point in timeline where num_gc advanced
+        // since the previous sample, returning the elapsed_ms of each such
+        // GC event. runtime/metrics only exposes GC as a monotonic cycle
+        // counter and a pause-time distribution, not individual pause
+        // timestamps, so a GC "event" here means "at least one collection
+        // completed between this sample and the last."
+        function gcEventMarkers(timeline) {
+            const events = [];
+            for (let i = 1; i < timeline.length; i++) {
+                if (timeline[i].stats.num_gc > timeline[i - 1].stats.num_gc) {
+                    events.push(timeline[i].elapsed_ms);
+                }
+            }
+            return events;
+        }
+
+        // renderRuntimeTimeline draws heap-in-use vs. GC goal over the
+        // course of a test as an inline SVG polyline chart, from the
+        // ~100ms GoStatsSnapshot samples in test.runtime_timeline, with a
+        // vertical marker at each elapsed_ms where num_gc advanced.
+        function renderRuntimeTimeline(timeline) {
+            const width = 600, height = 200, pad = 30;
+            const maxElapsed = timeline[timeline.length - 1].elapsed_ms;
+            const maxMB = Math.max.apply(null, timeline.map(function (s) {
+                return Math.max(s.stats.heap_inuse_mb, s.stats.heap_goal_mb);
+            })) || 1;
+
+            const x = function (ms) { return pad + (ms / maxElapsed) * (width - 2 * pad); };
+            const y = function (mb) { return height - pad - (mb / maxMB) * (height - 2 * pad); };
+
+            const toPoints = function (key) {
+                return timeline.map(function (s) {
+                    return x(s.elapsed_ms) + ',' + y(s.stats[key]);
+                }).join(' ');
+            };
+
+            const gcLines = gcEventMarkers(timeline).map(function (ms) {
+                return '<line x1="' + x(ms) + '" y1="' + pad + '" x2="' + x(ms) + '" y2="' + (height - pad) +
+                    '" stroke="#95a5a6" stroke-width="1" stroke-dasharray="2,2" />';
+            }).join('');
+
+            return '<h4 style="margin-top: 20px; color: #2c3e50;">' + t('heapVsGoalHeading') + '</h4>' +
+                '<div class="chart-container">' +
+                '<svg width="' + width + '" height="' + height + '" viewBox="0 0 ' + width + ' ' + height + '">' +
+                    gcLines +
+                    '<polyline fill="none" stroke="#667eea" stroke-width="2" points="' + toPoints('heap_inuse_mb') + '" />' +
+                    '<polyline fill="none" stroke="#e74c3c" stroke-width="2" stroke-dasharray="4,3" points="' + toPoints('heap_goal_mb') + '" />' +
+                '</svg>' +
+                '<div style="font-size: 0.8rem; color: #666;">' +
+                    '<span style="color: #667eea;">&#9632;</span> ' + t('legend_heapInUse') + ' &nbsp; ' +
+                    '<span style="color: #e74c3c;">&#9632;</span> ' + t('legend_gcGoal') + ' &nbsp; ' +
+                    '<span style="color: #95a5a6;">&#8942;</span> ' + t('legend_gcEvent') +
+                '</div>' +
+                '</div>';
+        }
+
+        // renderLatencyHistogram draws test.gc_pause_histogram (bucketed
+        // ms -> count from the /gc/pauses:seconds distribution, the closest
+        // this harness comes to a per-operation latency histogram since its
+        // PSI calls are single batched operations rather than per-item) as
+        // an inline SVG bar chart.
+        function renderLatencyHistogram(histogram) {
+            const width = 600, height = 160, pad = 30;
+            const maxCount = Math.max.apply(null, histogram.map(function (b) { return b.count; })) || 1;
+            const barWidth = (width - 2 * pad) / histogram.length;
+
+            const bars = histogram.map(function (b, i) {
+                const barHeight = (b.count / maxCount) * (height - 2 * pad);
+                const xPos = pad + i * barWidth;
+                return '<rect x="' + xPos + '" y="' + (height - pad - barHeight) + '" width="' + (barWidth - 2) +
+                    '" height="' + barHeight + '" fill="#9b59b6">' +
+                    '<title>' + b.upper_bound_ms.toFixed(2) + ' ms: ' + b.count + '</title>' +
+                    '</rect>';
+            }).join('');
+
+            return '<h4 style="margin-top: 20px; color: #2c3e50;">' + t('gcPauseDistHeading') + '</h4>' +
+                '<div class="chart-container">' +
+                '<svg width="' + width + '" height="' + height + '" viewBox="0 0 ' + width + ' ' + height + '">' +
+                    bars +
+                '</svg>' +
+                '<div style="font-size: 0.8rem; color: #666;">' + t('gcPauseDistCaption') + '</div>' +
+                '</div>';
+        }
+
         function renderPerformanceAnalysis(data) {
             const html = '<div class="test-metrics">' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Largest Dataset</span>' +
+                    '<span class="metric-label">' + t('perf_largestDataset') + '</span>' +
                     '<span class="metric-value">' + data.summary.largest_dataset_tested.toLocaleString() + ' records</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Total Data Processed</span>' +
+                    '<span class="metric-label">' + t('perf_totalDataProcessed') + '</span>' +
                     '<span class="metric-value">' + data.summary.total_data_processed.toLocaleString() + ' records</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Fastest Test</span>' +
+                    '<span class="metric-label">' + t('perf_fastestTest') + '</span>' +
                     '<span class="metric-value">' + data.summary.fastest_test + '</span>' +
                 '</div>' +
                 '<div class="metric">' +
-                    '<span class="metric-label">Slowest Test</span>' +
+                    '<span class="metric-label">' + t('perf_slowestTest') + '</span>' +
                     '<span class="metric-value">' + data.summary.slowest_test + '</span>' +
                 '</div>' +
             '</div>';
@@ -1113,10 +2054,123 @@ func generateHTMLReport(htmlPath, jsonPath string) error {
             document.getElementById('performanceAnalysis').innerHTML = html;
         }
 
-        window.addEventListener('load', loadData);
+        // historySeriesFor groups every successful run's metrics by
+        // test_name, in run order, for regression detection and trend
+        // charts. Runs where a test_name is missing or failed are skipped
+        // for that test_name's series rather than breaking the chart.
+        function historySeriesFor(runs, testName) {
+            const series = [];
+            runs.forEach(run => {
+                const test = (run.report.test_results || []).find(t => t.test_name === testName && t.success);
+                if (!test) return;
+                series.push({
+                    timestamp: run.timestamp,
+                    total_time_ms: test.total_time_ns / 1000000,
+                    throughput_ops_per_sec: test.throughput_ops_per_sec,
+                    peak_ram_mb: test.ram_analysis.peak_ram_mb,
+                    accuracy: test.accuracy,
+                });
+            });
+            return series;
+        }
+
+        function mean(values) {
+            return values.reduce((a, b) => a + b, 0) / values.length;
+        }
+
+        function stddev(values, avg) {
+            return Math.sqrt(mean(values.map(v => (v - avg) * (v - avg))));
+        }
+
+        // detectRegression flags the latest point in series against the
+        // mean/stddev of up to the prior 10 runs: a latency (total_time_ms)
+        // regression is > 2 sigma above the mean, a RAM regression is more
+        // than 10% above the mean. Returns null if there's not enough prior
+        // history (fewer than 2 runs) to judge against.
+        function detectRegression(series) {
+            if (series.length < 3) return null;
+            const latest = series[series.length - 1];
+            const windowed = series.slice(Math.max(0, series.length - 11), series.length - 1);
+
+            const latencyMean = mean(windowed.map(s => s.total_time_ms));
+            const latencyStddev = stddev(windowed.map(s => s.total_time_ms), latencyMean);
+            const latencyRegressed = latest.total_time_ms > latencyMean + 2 * latencyStddev;
+
+            const ramMean = mean(windowed.map(s => s.peak_ram_mb));
+            const ramRegressed = ramMean > 0 && latest.peak_ram_mb > ramMean * 1.1;
+
+            return { latencyRegressed, ramRegressed, latencyMean, ramMean };
+        }
+
+        // renderHistorySeriesChart draws one metric across runs as an
+        // inline SVG polyline, in the same style as renderRuntimeTimeline,
+        // with the latest point drawn as a red circle when regressed.
+        function renderHistorySeriesChart(series, key, label, color, regressed) {
+            const width = 560, height = 160, pad = 30;
+            const values = series.map(s => s[key]);
+            const maxV = Math.max.apply(null, values) || 1;
+            const minV = Math.min(0, Math.min.apply(null, values));
+
+            const x = i => pad + (i / (series.length - 1 || 1)) * (width - 2 * pad);
+            const y = v => height - pad - ((v - minV) / (maxV - minV || 1)) * (height - 2 * pad);
+
+            const points = series.map((s, i) => x(i) + ',' + y(s[key])).join(' ');
+            const last = series.length - 1;
+            const latestMarker = '<circle cx="' + x(last) + '" cy="' + y(series[last][key]) +
+                '" r="5" fill="' + (regressed ? '#e74c3c' : color) + '" />';
+
+            return '<div class="chart-container" style="height: auto;">' +
+                '<div style="font-size: 0.85rem; color: #2c3e50; margin-bottom: 0.25rem;">' + label +
+                (regressed ? ' <span style="color: #e74c3c; font-weight: 600;">&#9888; ' + t('regressionBadge') + '</span>' : '') +
+                '</div>' +
+                '<svg width="' + width + '" height="' + height + '" viewBox="0 0 ' + width + ' ' + height + '">' +
+                    '<polyline fill="none" stroke="' + color + '" stroke-width="2" points="' + points + '" />' +
+                    latestMarker +
+                '</svg>' +
+                '</div>';
+        }
+
+        // renderHistoryTrends plots throughput/peak-RAM/accuracy per
+        // test_name across every run in the history index, and flags the
+        // latest run red wherever detectRegression trips.
+        function renderHistoryTrends(runs) {
+            document.getElementById('historySection').style.display = 'block';
+
+            const testNames = Array.from(new Set(
+                runs.flatMap(run => (run.report.test_results || []).map(t => t.test_name))
+            ));
+
+            const html = testNames.map(testName => {
+                const series = historySeriesFor(runs, testName);
+                if (series.length < 2) return '';
+
+                const regression = detectRegression(series);
+                const latencyFlag = regression ? regression.latencyRegressed : false;
+                const ramFlag = regression ? regression.ramRegressed : false;
+
+                return '<div class="test-metrics" style="margin-bottom: 1.5rem;">' +
+                    '<h4 style="grid-column: 1 / -1; color: #2c3e50;">' + testName +
+                        (latencyFlag || ramFlag ? ' <span style="color: #e74c3c;">&#9888; ' + t('regressionDetected') + '</span>' : '') +
+                    '</h4>' +
+                    renderHistorySeriesChart(series, 'total_time_ms', t('hist_totalTime'), '#667eea', latencyFlag) +
+                    renderHistorySeriesChart(series, 'peak_ram_mb', t('hist_peakRam'), '#e67e22', ramFlag) +
+                    renderHistorySeriesChart(series, 'throughput_ops_per_sec', t('hist_throughput'), '#2ecc71', false) +
+                    renderHistorySeriesChart(series, 'accuracy', t('hist_accuracy'), '#16a085', false) +
+                '</div>';
+            }).join('');
+
+            document.getElementById('historyTrends').innerHTML =
+                html || '<div style="color: #666;">' + t('historyNoData') + '</div>';
+        }
+
+        window.addEventListener('load', function () {
+            document.getElementById('langSelect').value = currentLang;
+            applyStaticLabels();
+            loadData();
+        });
     </script>
 </body>
-</html>`;
+</html>`
 
 	return os.WriteFile(htmlPath, []byte(htmlContent), 0644)
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/SanthoshCheemala/LE-PSI/pkg/bench"
+)
+
+// metricsWriter streams live per-sample RAM/GoStats and per-test phase
+// timings as InfluxDB line protocol, when -metrics-sink is set. It is nil
+// (disabled) otherwise, and every call site below checks for that.
+var metricsWriter *bench.LineWriter
+
+// metricsSinkCloser is the underlying file/socket metricsWriter wraps, kept
+// around so main can close it on exit.
+var metricsSinkCloser io.Closer
+
+// openMetricsSink opens spec (a file path, or a tcp://host:port /
+// udp://host:port socket) via bench.OpenLineSink and wires metricsWriter to
+// it. Call once, at startup.
+func openMetricsSink(spec string) {
+	sink, err := bench.OpenLineSink(spec)
+	if err != nil {
+		log.Printf("metrics sink %s: %v (continuing without live metrics)", spec, err)
+		return
+	}
+	metricsSinkCloser = sink
+	metricsWriter = bench.NewLineWriter(sink)
+	log.Printf("streaming live metrics as line protocol to %s", spec)
+}
+
+// closeMetricsSink flushes and closes the sink opened by openMetricsSink, if
+// any.
+func closeMetricsSink() {
+	if metricsSinkCloser == nil {
+		return
+	}
+	if err := metricsSinkCloser.Close(); err != nil {
+		log.Printf("metrics sink: close: %v", err)
+	}
+}
+
+// lineTagsFor returns the test/server_size/client_size tags every line this
+// package emits for test carries.
+func lineTagsFor(test ScalabilityTest) map[string]string {
+	return map[string]string{
+		"test_name":   test.Name,
+		"server_size": strconv.Itoa(test.ServerSize),
+		"client_size": strconv.Itoa(test.ClientSize),
+	}
+}
+
+// wireMetricsSink hooks rec and sampler to stream every sample they collect
+// as line protocol, tagged with test and the given phase getter (the
+// caller's current phase, since bench.Record/GoStatsSnapshot don't carry
+// one themselves). It is a no-op when metricsWriter is nil.
+func wireMetricsSink(test ScalabilityTest, rec *bench.Recorder, sampler *RuntimeSampler, currentPhase func() string) {
+	if metricsWriter == nil {
+		return
+	}
+
+	tags := lineTagsFor(test)
+
+	rec.OnRecord(func(record bench.Record) {
+		if record.Sample == nil {
+			return
+		}
+		s := record.Sample
+		phaseTags := withPhase(tags, currentPhase())
+		fields := map[string]float64{
+			"heap_alloc_mb":     s.HeapAllocMB,
+			"heap_inuse_mb":     s.HeapInUseMB,
+			"rss_mb":            s.VmRSSMB,
+			"peak_mb":           s.VmPeakMB,
+			"swap_mb":           s.VmSwapMB,
+			"cgroup_current_mb": s.CgroupMemoryCurrentMB,
+			"cgroup_max_mb":     s.CgroupMemoryMaxMB,
+			"num_goroutines":    float64(s.NumGoroutines),
+		}
+		if err := metricsWriter.WriteLine("le_psi_ram", phaseTags, fields, record.Timestamp); err != nil {
+			log.Printf("metrics sink: %v", err)
+		}
+	})
+
+	sampler.OnSnapshot(func(snap GoStatsSnapshot) {
+		phaseTags := withPhase(tags, currentPhase())
+		if err := metricsWriter.WriteLine("le_psi_goruntime", phaseTags, goStatsFields(snap.Stats), time.Now()); err != nil {
+			log.Printf("metrics sink: %v", err)
+		}
+	})
+}
+
+// emitPhaseTimings writes the one-shot psi_phase_ns line at the end of a
+// test, once InitializationTime/EncryptionTime/IntersectionTime are known.
+func emitPhaseTimings(test ScalabilityTest, result TestResult) {
+	if metricsWriter == nil {
+		return
+	}
+	err := metricsWriter.WriteLine("psi_phase_ns", lineTagsFor(test), map[string]float64{
+		"initialization_ns": float64(result.InitializationTime.Nanoseconds()),
+		"encryption_ns":     float64(result.EncryptionTime.Nanoseconds()),
+		"intersection_ns":   float64(result.IntersectionTime.Nanoseconds()),
+	}, time.Now())
+	if err != nil {
+		log.Printf("metrics sink: %v", err)
+	}
+}
+
+func withPhase(tags map[string]string, phase string) map[string]string {
+	out := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		out[k] = v
+	}
+	out["phase"] = phase
+	return out
+}
+
+// goStatsFields lists every GoStats scalar as a line-protocol field.
+func goStatsFields(s GoStats) map[string]float64 {
+	return map[string]float64{
+		"allocated_memory_mb":  s.AllocatedMemoryMB,
+		"total_allocated_mb":   s.TotalAllocatedMB,
+		"system_memory_mb":     s.SystemMemoryMB,
+		"heap_alloc_mb":        s.HeapAllocMB,
+		"heap_sys_mb":          s.HeapSysMB,
+		"heap_idle_mb":         s.HeapIdleMB,
+		"heap_inuse_mb":        s.HeapInUseMB,
+		"heap_goal_mb":         s.HeapGoalMB,
+		"stack_inuse_mb":       s.StackInUseMB,
+		"num_gc":               float64(s.NumGC),
+		"gc_cpu_percentage":    s.GCCPUPercentage,
+		"last_gc_pause_ms":     s.LastGCPauseMs,
+		"total_gc_pause_ms":    s.TotalGCPauseMs,
+		"gc_pause_p50_ms":      s.GCPauseP50Ms,
+		"gc_pause_p90_ms":      s.GCPauseP90Ms,
+		"gc_pause_p99_ms":      s.GCPauseP99Ms,
+		"sched_latency_p50_ms": s.SchedLatencyP50Ms,
+		"sched_latency_p90_ms": s.SchedLatencyP90Ms,
+		"sched_latency_p99_ms": s.SchedLatencyP99Ms,
+		"mutex_wait_total_ms":  s.MutexWaitTotalMs,
+		"num_goroutines":       float64(s.NumGoroutines),
+		"num_cpu":              float64(s.NumCPU),
+		"gomaxprocs":           float64(s.GOMAXPROCS),
+		"mallocs":              float64(s.Mallocs),
+		"frees":                float64(s.Frees),
+		"live_objects":         float64(s.LiveObjects),
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCgroupAbortFraction is the current-to-max cgroup memory ratio a ramGuard
+// aborts at by default; -cgroup-abort-fraction overrides it.
+const defaultCgroupAbortFraction = 0.85
+
+// cgroupAbortFraction is set from -cgroup-abort-fraction in main().
+var cgroupAbortFraction = defaultCgroupAbortFraction
+
+// ramGuard watches process RSS and cgroup memory pressure in the background
+// while a test runs, so runScalabilityTest can abort before the host OOMs
+// instead of after - the "H100 crashes at 1K records" failure mode this
+// exists to catch.
+type ramGuard struct {
+	maxRAMMB float64
+	stop     chan struct{}
+	done     chan struct{}
+
+	mu       sync.Mutex
+	exceeded bool
+	reason   string
+}
+
+// newRAMGuard starts watching immediately. maxRAMMB of 0 disables the RSS
+// check; the cgroup ratio check (against cgroupAbortFraction) always runs,
+// since it's harmless when no cgroup limit is present (readCgroupMemoryMBDirect
+// returns maxMB=0, which disables the ratio check too).
+func newRAMGuard(maxRAMMB float64) *ramGuard {
+	g := &ramGuard{maxRAMMB: maxRAMMB, stop: make(chan struct{}), done: make(chan struct{})}
+	go g.run()
+	return g
+}
+
+func (g *ramGuard) run() {
+	defer close(g.done)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+			g.check()
+		}
+	}
+}
+
+func (g *ramGuard) check() {
+	rssMB, _, _ := currentProcessRAMMB()
+	cgroupCurrentMB, cgroupMaxMB := readCgroupMemoryMBDirect()
+
+	var reason string
+	switch {
+	case g.maxRAMMB > 0 && rssMB > g.maxRAMMB:
+		reason = fmt.Sprintf("RSS %.1fMB exceeds MaxRAM_MB %.1fMB", rssMB, g.maxRAMMB)
+	case cgroupMaxMB > 0 && cgroupCurrentMB/cgroupMaxMB > cgroupAbortFraction:
+		reason = fmt.Sprintf("cgroup memory.current/max ratio %.2f exceeds %.2f", cgroupCurrentMB/cgroupMaxMB, cgroupAbortFraction)
+	default:
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.exceeded {
+		g.exceeded = true
+		g.reason = reason
+	}
+}
+
+// Exceeded reports whether the threshold was crossed since newRAMGuard, and
+// why, the first time it happened.
+func (g *ramGuard) Exceeded() (bool, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.exceeded, g.reason
+}
+
+// Stop halts the background goroutine. It does not need to drain anything,
+// unlike bench.Recorder.Close, since ramGuard never writes to disk.
+func (g *ramGuard) Stop() {
+	close(g.stop)
+	<-g.done
+}
+
+// readCgroupMemoryMBDirect duplicates pkg/bench's unexported cgroup read so
+// ramGuard can poll it every 100ms for threshold checking without waiting
+// for a bench.Recorder sample, which runs on its own, coarser interval.
+func readCgroupMemoryMBDirect() (currentMB, maxMB float64) {
+	if cur, ok := readCgroupValueMBDirect("/sys/fs/cgroup/memory.current"); ok {
+		currentMB = cur
+		if max, ok := readCgroupValueMBDirect("/sys/fs/cgroup/memory.max"); ok {
+			maxMB = max
+		}
+		return currentMB, maxMB
+	}
+
+	if cur, ok := readCgroupValueMBDirect("/sys/fs/cgroup/memory/memory.usage_in_bytes"); ok {
+		currentMB = cur
+		if max, ok := readCgroupValueMBDirect("/sys/fs/cgroup/memory/memory.limit_in_bytes"); ok {
+			maxMB = max
+		}
+		return currentMB, maxMB
+	}
+
+	return 0, 0
+}
+
+func readCgroupValueMBDirect(path string) (mb float64, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	s := strings.TrimSpace(string(data))
+	if s == "max" {
+		return 0, false
+	}
+	bytes, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if bytes > 1<<62 {
+		return 0, false
+	}
+	return float64(bytes) / 1024 / 1024, true
+}
@@ -1,18 +1,32 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
 	"github.com/SanthoshCheemala/LE-PSI/pkg/psi"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/psi/grpcapi"
+	"github.com/SanthoshCheemala/LE-PSI/pkg/psi/grpcapi/lepsipb"
 	"github.com/SanthoshCheemala/LE-PSI/utils"
 )
 
+// grpcListenAddr is where LEPSI's streaming gRPC service listens, alongside
+// the JSON/HTTP handlers on :8080. A separate port keeps the gRPC service
+// (HTTP/2, its own framing) from sharing a net/http ServeMux with the
+// existing handlers.
+const grpcListenAddr = ":50051"
+
 var (
 	serverCtx     *psi.ServerInitContext
 	serverData    []interface{}
@@ -22,18 +36,19 @@ var (
 )
 
 type StatusResponse struct {
-	Status     string    `json:"status"`
-	Message    string    `json:"message"`
-	DataSize   int       `json:"data_size"`
-	Uptime     string    `json:"uptime"`
-	Requests   int       `json:"requests_handled"`
-	ServerTime time.Time `json:"server_time"`
+	Status      string    `json:"status"`
+	Message     string    `json:"message"`
+	DataSize    int       `json:"data_size"`
+	Uptime      string    `json:"uptime"`
+	Requests    int       `json:"requests_handled"`
+	ServerTime  time.Time `json:"server_time"`
+	DataVersion uint64    `json:"data_version"`
 }
 
 type ParamsResponse struct {
 	Params    *psi.SerializableParams `json:"params"`
-	Message   string                   `json:"message"`
-	Timestamp time.Time                `json:"timestamp"`
+	Message   string                  `json:"message"`
+	Timestamp time.Time               `json:"timestamp"`
 }
 
 type IntersectionRequest struct {
@@ -53,19 +68,19 @@ type IntersectionResponse struct {
 const serverDataFilePath = "../../data/server_data.json"
 
 type ServerEntity struct {
-	EntityID       string   `json:"entity_id"`
-	Name           string   `json:"name"`
-	Aliases        []string `json:"aliases"`
-	DOB            string   `json:"dob"`
-	Country        string   `json:"country"`
-	RiskLevel      string   `json:"risk_level"`
-	SanctionProgram string  `json:"sanction_program"`
-	SanctionDate   string   `json:"sanction_date"`
-	PassportNumber *string  `json:"passport_number"`
-	NationalID     *string  `json:"national_id"`
-	PSIKey         string   `json:"psi_key"`
-	PSIHash        string   `json:"psi_hash"`
-	LastUpdated    string   `json:"last_updated"`
+	EntityID        string   `json:"entity_id"`
+	Name            string   `json:"name"`
+	Aliases         []string `json:"aliases"`
+	DOB             string   `json:"dob"`
+	Country         string   `json:"country"`
+	RiskLevel       string   `json:"risk_level"`
+	SanctionProgram string   `json:"sanction_program"`
+	SanctionDate    string   `json:"sanction_date"`
+	PassportNumber  *string  `json:"passport_number"`
+	NationalID      *string  `json:"national_id"`
+	PSIKey          string   `json:"psi_key"`
+	PSIHash         string   `json:"psi_hash"`
+	LastUpdated     string   `json:"last_updated"`
 }
 
 func main() {
@@ -104,13 +119,39 @@ func main() {
 	fmt.Println("Server initialized successfully")
 	fmt.Println("Server listening on http://localhost:8080")
 
+	psi.DefaultMetrics().MustRegisterOn(prometheus.DefaultRegisterer)
+
 	http.HandleFunc("/api/status", handleStatus)
 	http.HandleFunc("/api/params", handleGetParams)
-	http.HandleFunc("/api/intersect", handleIntersection)
+	http.HandleFunc("/api/params.bin", handleGetParamsBinary)
+	http.HandleFunc("/api/intersect", psi.MetricsMiddleware(psi.DefaultMetrics(), handleIntersection))
+	http.Handle("/metrics", psi.Handler())
+
+	go serveGRPC()
 
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
 
+// serveGRPC starts the streaming LEPSI gRPC service (see pkg/psi/grpcapi)
+// alongside the JSON/HTTP handlers, so a client that wants to push
+// ciphertexts and receive matches as a stream - instead of one
+// buffer-the-whole-request/response JSON round trip through
+// handleIntersection - doesn't need a separate server process.
+func serveGRPC() {
+	lis, err := net.Listen("tcp", grpcListenAddr)
+	if err != nil {
+		log.Fatalf("grpc: listen on %s: %v", grpcListenAddr, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	lepsipb.RegisterLEPSIServer(grpcServer, grpcapi.NewServer(serverCtx))
+
+	fmt.Printf("gRPC LEPSI service listening on %s\n", grpcListenAddr)
+	if err := grpcServer.Serve(lis); err != nil {
+		log.Fatalf("grpc: serve: %v", err)
+	}
+}
+
 // loadArrayFromJSON loads a generic JSON array ([]interface{})
 func loadArrayFromJSON(path string) ([]interface{}, error) {
 	b, err := os.ReadFile(path)
@@ -140,12 +181,13 @@ func loadServerEntities(path string) ([]ServerEntity, error) {
 func handleStatus(w http.ResponseWriter, r *http.Request) {
 	mu.RLock()
 	response := StatusResponse{
-		Status:     "running",
-		Message:    "Server is healthy",
-		DataSize:   len(serverData),
-		Uptime:     time.Since(serverStarted).String(),
-		Requests:   requestCount,
-		ServerTime: time.Now(),
+		Status:      "running",
+		Message:     "Server is healthy",
+		DataSize:    len(serverData),
+		Uptime:      time.Since(serverStarted).String(),
+		Requests:    requestCount,
+		ServerTime:  time.Now(),
+		DataVersion: serverCtx.DataVersion(),
 	}
 	mu.RUnlock()
 
@@ -172,6 +214,32 @@ func handleGetParams(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// handleGetParamsBinary serves the same public parameters as handleGetParams
+// in the binary wire format (see psi.SerializableParams.MarshalBinary)
+// instead of JSON, for clients that want the tens-to-hundreds-of-MB params
+// blob without the JSON decimal-per-coefficient overhead.
+func handleGetParamsBinary(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	requestCount++
+	mu.Unlock()
+
+	pp, msg, le := psi.GetPublicParameters(serverCtx)
+	serializedParams := psi.SerializeParameters(pp, msg, le)
+
+	data, err := serializedParams.MarshalBinary()
+	if err != nil {
+		http.Error(w, "Failed to encode parameters", 500)
+		log.Printf("params.bin: marshal: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-lepsi-params")
+	log.Printf("Streaming binary parameters to [%s] (%d bytes)", r.RemoteAddr, len(data))
+	if _, err := io.Copy(w, bytes.NewReader(data)); err != nil {
+		log.Printf("params.bin: write: %v", err)
+	}
+}
+
 func handleIntersection(w http.ResponseWriter, r *http.Request) {
 	mu.Lock()
 	requestCount++
@@ -1,8 +1,6 @@
 package utils
 
 import (
-	"crypto/sha256"
-	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -78,7 +76,7 @@ func serializeSlice(val reflect.Value) (string, error) {
 func serializeMap(val reflect.Value) (string, error) {
 	keys := val.MapKeys()
 	keyStrings := make([]string, len(keys))
-	
+
 	for i, key := range keys {
 		serialized, err := serializeValue(key)
 		if err != nil {
@@ -109,22 +107,22 @@ func serializeMap(val reflect.Value) (string, error) {
 func serializeStruct(val reflect.Value) (string, error) {
 	typ := val.Type()
 	fields := make([]string, 0, val.NumField())
-	
+
 	for i := 0; i < val.NumField(); i++ {
 		field := val.Field(i)
 		fieldType := typ.Field(i)
-		
+
 		if !field.CanInterface() {
 			continue
 		}
-		
+
 		serialized, err := serializeValue(field)
 		if err != nil {
 			return "", err
 		}
 		fields = append(fields, fieldType.Name+":"+serialized)
 	}
-	
+
 	sort.Strings(fields)
 	return "{" + join(fields, ",") + "}", nil
 }
@@ -148,7 +146,7 @@ func PrepareDataForPSI(dataset []interface{}) ([]string, error) {
 	}
 
 	hashedData := make([]string, len(dataset))
-	
+
 	for i, data := range dataset {
 		serialized, err := SerializeData(data)
 		if err != nil {
@@ -160,14 +158,6 @@ func PrepareDataForPSI(dataset []interface{}) ([]string, error) {
 	return hashedData, nil
 }
 
-// HashDataPoints converts serialized strings to uint64 hashes using SHA-256.
-func HashDataPoints(serializedData []string) []uint64 {
-	hashes := make([]uint64, len(serializedData))
-	
-	for i, data := range serializedData {
-		hash := sha256.Sum256([]byte(data))
-		hashes[i] = binary.BigEndian.Uint64(hash[:8])
-	}
-	
-	return hashes
-}
+// HashDataPoints has moved to hasher.go, which generalizes it to the
+// pluggable Hasher interface while preserving its original SHA-256
+// behavior.
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"lukechampine.com/blake3"
+)
+
+// Hasher maps one serialized PSI data point (see SerializeData) to a uint64
+// tree-index hash. HashDataPoints used to hardcode SHA-256 truncated to its
+// first 8 bytes, which loses entropy and offers no protection against
+// offline dictionary attacks on low-entropy inputs like names or DOBs.
+// Hasher lets a caller swap in a domain-separated or KDF-hardened scheme via
+// HashDataPointsWith instead.
+type Hasher interface {
+	// Scheme identifies this Hasher for SerializableParams.HashScheme, so a
+	// peer knows which algorithm produced the hashes it receives.
+	Scheme() string
+	// Hash returns the uint64 hash of one serialized data point.
+	Hash(data string) uint64
+}
+
+// SHA256Hasher is HashDataPoints' original scheme: SHA-256 truncated to its
+// first 8 bytes. It has no domain separation - the same input always hashes
+// to the same value regardless of caller - which is fine for a single
+// trusted deployment but leaves small input domains open to offline
+// dictionary attacks.
+type SHA256Hasher struct{}
+
+func (SHA256Hasher) Scheme() string { return "sha256" }
+
+func (SHA256Hasher) Hash(data string) uint64 {
+	sum := sha256.Sum256([]byte(data))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// HKDFHasher derives each hash via HKDF-SHA256 keyed on Salt, giving domain
+// separation that plain SHA-256 truncation doesn't have: two deployments
+// using different Salts get unlinkable hashes for the same input, closing
+// off offline dictionary attacks on small domains.
+type HKDFHasher struct {
+	Salt []byte
+}
+
+func (h HKDFHasher) Scheme() string { return "hkdf-sha256" }
+
+func (h HKDFHasher) Hash(data string) uint64 {
+	return Expand8([]byte(data), h.Salt, nil)
+}
+
+// BLAKE3Hasher hashes with BLAKE3, a faster and more conservatively
+// constructed alternative to truncated SHA-256.
+type BLAKE3Hasher struct{}
+
+func (BLAKE3Hasher) Scheme() string { return "blake3" }
+
+func (BLAKE3Hasher) Hash(data string) uint64 {
+	sum := blake3.Sum256([]byte(data))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// Expand8 runs HKDF-SHA256(secret, salt, info) and returns the first 8
+// output bytes as a big-endian uint64. It backs HKDFHasher, and
+// psi.CuckooSeeds reuses it for the same keyed-expansion construction
+// rather than keeping its own copy.
+func Expand8(secret, salt, info []byte) uint64 {
+	kdf := hkdf.New(func() hash.Hash { return sha256.New() }, secret, salt, info)
+	var out [8]byte
+	if _, err := io.ReadFull(kdf, out[:]); err != nil {
+		panic(fmt.Sprintf("utils: hkdf expand: %v", err))
+	}
+	return binary.BigEndian.Uint64(out[:])
+}
+
+// HashDataPointsWith is HashDataPoints generalized to any Hasher.
+func HashDataPointsWith(serializedData []string, h Hasher) []uint64 {
+	hashes := make([]uint64, len(serializedData))
+	for i, data := range serializedData {
+		hashes[i] = h.Hash(data)
+	}
+	return hashes
+}
+
+// HashDataPoints converts serialized strings to uint64 hashes using
+// SHA256Hasher, preserving HashDataPoints' original SHA-256-truncation
+// behavior for existing callers.
+func HashDataPoints(serializedData []string) []uint64 {
+	return HashDataPointsWith(serializedData, SHA256Hasher{})
+}
+
+// This package originally also shipped a CuckooHasher/HashDataPointsBucketed
+// pair meant to bucket serialized data points into k candidate indices for
+// ClientEncrypt/DetectIntersectionWithContext to consume directly. It was
+// never wired into either - pkg/psi's actual cuckoo-hashing speedup
+// (psi.CuckooSeeds/psi.CuckooLayout, see pkg/psi/cuckoo.go) was built
+// independently against ServerInitContext and SHA256/HKDF/BLAKE3Hasher's
+// plain uint64-per-element Hasher interface instead, and is what
+// ServerInitialize's WithCuckooHashing option and ClientEncryptCuckoo
+// actually use. CuckooHasher was removed as dead code rather than wired in
+// a second time; use psi.CuckooSeeds for cuckoo-hashed PSI.
@@ -1,20 +1,18 @@
 package utils
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"time"
 )
 
 // PSIReport represents a simplified PSI analysis report.
 // This structure contains essential metrics for PSI operations.
 type PSIReport struct {
-	Summary    SummaryMetrics    `json:"summary"`
-	Parameters ParameterMetrics  `json:"parameters"`
-	Timing     TimingMetrics     `json:"timing"`
-	Noise      NoiseMetrics      `json:"noise"`
-	Metadata   ReportMetadata    `json:"metadata"`
+	Summary    SummaryMetrics   `json:"summary"`
+	Parameters ParameterMetrics `json:"parameters"`
+	Timing     TimingMetrics    `json:"timing"`
+	Noise      NoiseMetrics     `json:"noise"`
+	Metadata   ReportMetadata   `json:"metadata"`
 }
 
 // SummaryMetrics contains high-level PSI operation statistics.
@@ -23,6 +21,7 @@ type SummaryMetrics struct {
 	TotalMatches    int     `json:"totalMatches"`
 	TotalErrors     int     `json:"totalErrors"`
 	SuccessRate     float64 `json:"successRate"`
+	SkippedCount    int     `json:"skippedCount"`
 }
 
 // ParameterMetrics contains cryptographic parameter information.
@@ -35,6 +34,11 @@ type ParameterMetrics struct {
 	NumSlots      int     `json:"numSlots"`
 	LoadFactor    float64 `json:"loadFactor"`
 	CollisionProb float64 `json:"collisionProb"`
+	FPRate        float64 `json:"fpRate"`
+	// PredictedSafeDepth is psi.NoiseBudget.PredictedSafeDepth's estimate of
+	// the maximum witness-tree depth these parameters can still decrypt
+	// correctly, given the noise growth observed so far.
+	PredictedSafeDepth int `json:"predictedSafeDepth"`
 }
 
 // TimingMetrics contains execution time breakdowns.
@@ -44,13 +48,20 @@ type TimingMetrics struct {
 	ServerEncryption string  `json:"serverEncryption"`
 	DecryptionTime   string  `json:"decryptionTime"`
 	Throughput       float64 `json:"throughput"`
+	// P50/P95/P99LatencyMs are per-event processing latencies, in
+	// milliseconds, reported by a streaming PSI server (see
+	// psi.StreamingServer.LatencyReport). They are left at zero for the
+	// one-shot batch flow, which has no meaningful per-event latency.
+	P50LatencyMs float64 `json:"p50LatencyMs"`
+	P95LatencyMs float64 `json:"p95LatencyMs"`
+	P99LatencyMs float64 `json:"p99LatencyMs"`
 }
 
 // NoiseMetrics contains noise analysis statistics.
 type NoiseMetrics struct {
-	MaxNoise      float64        `json:"maxNoise"`
-	AvgNoise      float64        `json:"avgNoise"`
-	Distribution  map[string]int `json:"distribution"`
+	MaxNoise     float64        `json:"maxNoise"`
+	AvgNoise     float64        `json:"avgNoise"`
+	Distribution map[string]int `json:"distribution"`
 }
 
 // ReportMetadata contains report generation information.
@@ -152,19 +163,11 @@ func WritePSIReport(
 		},
 	}
 
-	file, err := os.Create(jsonPath)
-	if err != nil {
-		return fmt.Errorf("failed to create report file: %w", err)
+	if err := NewJSONReporter(jsonPath).Write(report); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(report); err != nil {
-		return fmt.Errorf("failed to encode report: %w", err)
-	}
-
-	fmt.Printf("âœ“ Report saved: %s\n", jsonPath)
+	fmt.Printf("Report saved: %s\n", jsonPath)
 	return nil
 }
 
@@ -186,4 +189,4 @@ func WriteEnhancedPSIReport(
 		duration, encDuration, serverEncDuration, decDuration, leAnalysis); err != nil {
 		fmt.Printf("Error writing report: %v\n", err)
 	}
-}
\ No newline at end of file
+}
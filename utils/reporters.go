@@ -0,0 +1,305 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Reporter writes a PSIReport to some destination — a file, a scrape
+// endpoint, a dashboard page. WritePSIReport uses a JSONReporter internally;
+// callers that want CSV/Prometheus/HTML output alongside (or instead of)
+// JSON construct the Reporter they need directly, or combine several with
+// MultiReporter.
+type Reporter interface {
+	Write(report PSIReport) error
+}
+
+// JSONReporter writes a report as indented JSON, the same format
+// WritePSIReport has always produced.
+type JSONReporter struct {
+	Path string
+}
+
+// NewJSONReporter creates a JSONReporter that writes to path.
+func NewJSONReporter(path string) *JSONReporter {
+	return &JSONReporter{Path: path}
+}
+
+// Write implements Reporter.
+func (r *JSONReporter) Write(report PSIReport) error {
+	file, err := os.Create(r.Path)
+	if err != nil {
+		return fmt.Errorf("json reporter: create %s: %w", r.Path, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("json reporter: encode: %w", err)
+	}
+	return nil
+}
+
+// CSVReporter appends one row per run to Path, writing a header line only
+// when the file is first created. This suits scripted parameter sweeps that
+// call Write once per configuration and want every run in one spreadsheet.
+type CSVReporter struct {
+	Path string
+}
+
+// NewCSVReporter creates a CSVReporter that appends to path.
+func NewCSVReporter(path string) *CSVReporter {
+	return &CSVReporter{Path: path}
+}
+
+var csvColumns = []string{
+	"timestamp", "totalOperations", "totalMatches", "totalErrors", "successRate", "skippedCount",
+	"q", "qBits", "d", "n", "layers", "numSlots", "loadFactor", "collisionProb", "fpRate", "predictedSafeDepth",
+	"totalDuration", "encryptionTime", "serverEncryption", "decryptionTime", "throughput",
+	"p50LatencyMs", "p95LatencyMs", "p99LatencyMs",
+	"maxNoise", "avgNoise",
+}
+
+// Write implements Reporter.
+func (r *CSVReporter) Write(report PSIReport) error {
+	writeHeader := true
+	if info, err := os.Stat(r.Path); err == nil && info.Size() > 0 {
+		writeHeader = false
+	}
+
+	file, err := os.OpenFile(r.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("csv reporter: open %s: %w", r.Path, err)
+	}
+	defer file.Close()
+
+	if writeHeader {
+		if _, err := fmt.Fprintln(file, strings.Join(csvColumns, ",")); err != nil {
+			return fmt.Errorf("csv reporter: write header: %w", err)
+		}
+	}
+
+	row := []string{
+		report.Metadata.Timestamp,
+		fmt.Sprint(report.Summary.TotalOperations),
+		fmt.Sprint(report.Summary.TotalMatches),
+		fmt.Sprint(report.Summary.TotalErrors),
+		fmt.Sprint(report.Summary.SuccessRate),
+		fmt.Sprint(report.Summary.SkippedCount),
+		fmt.Sprint(report.Parameters.Q),
+		fmt.Sprint(report.Parameters.QBits),
+		fmt.Sprint(report.Parameters.D),
+		fmt.Sprint(report.Parameters.N),
+		fmt.Sprint(report.Parameters.Layers),
+		fmt.Sprint(report.Parameters.NumSlots),
+		fmt.Sprint(report.Parameters.LoadFactor),
+		fmt.Sprint(report.Parameters.CollisionProb),
+		fmt.Sprint(report.Parameters.FPRate),
+		fmt.Sprint(report.Parameters.PredictedSafeDepth),
+		report.Timing.TotalDuration,
+		report.Timing.EncryptionTime,
+		report.Timing.ServerEncryption,
+		report.Timing.DecryptionTime,
+		fmt.Sprint(report.Timing.Throughput),
+		fmt.Sprint(report.Timing.P50LatencyMs),
+		fmt.Sprint(report.Timing.P95LatencyMs),
+		fmt.Sprint(report.Timing.P99LatencyMs),
+		fmt.Sprint(report.Noise.MaxNoise),
+		fmt.Sprint(report.Noise.AvgNoise),
+	}
+	if _, err := fmt.Fprintln(file, strings.Join(row, ",")); err != nil {
+		return fmt.Errorf("csv reporter: write row: %w", err)
+	}
+	return nil
+}
+
+// PromReporter writes a report as Prometheus text exposition format, the
+// format a node_exporter textfile collector expects: it overwrites Path in
+// full on every Write (textfile collectors always read the latest state,
+// not a history), with one gauge per summary count/rate, load-factor,
+// collision and false-positive estimate, throughput/latency figure and noise
+// figure, each preceded by its own "# HELP"/"# TYPE" lines. The ring and
+// tree parameters (Q, QBits, D, N, Layers, NumSlots) and PredictedSafeDepth
+// are not exported.
+type PromReporter struct {
+	Path string
+}
+
+// NewPromReporter creates a PromReporter that writes to path.
+func NewPromReporter(path string) *PromReporter {
+	return &PromReporter{Path: path}
+}
+
+type promMetric struct {
+	name  string
+	help  string
+	mtype string
+	value float64
+}
+
+// Write implements Reporter.
+func (r *PromReporter) Write(report PSIReport) error {
+	metrics := []promMetric{
+		{"lepsi_report_total_operations", "Total PSI operations in the most recent report.", "gauge", float64(report.Summary.TotalOperations)},
+		{"lepsi_report_total_matches", "Total intersection matches in the most recent report.", "gauge", float64(report.Summary.TotalMatches)},
+		{"lepsi_report_total_errors", "Total errors in the most recent report.", "gauge", float64(report.Summary.TotalErrors)},
+		{"lepsi_report_success_rate", "Success rate (percent) in the most recent report.", "gauge", report.Summary.SuccessRate},
+		{"lepsi_report_skipped_count", "Client queries skipped by the Bloom prefilter in the most recent report.", "gauge", float64(report.Summary.SkippedCount)},
+		{"lepsi_report_load_factor", "Witness tree load factor in the most recent report.", "gauge", report.Parameters.LoadFactor},
+		{"lepsi_report_collision_prob", "Estimated hash collision probability in the most recent report.", "gauge", report.Parameters.CollisionProb},
+		{"lepsi_report_fp_rate", "Estimated Bloom prefilter false-positive rate in the most recent report.", "gauge", report.Parameters.FPRate},
+		{"lepsi_report_throughput_ops_per_second", "Throughput in the most recent report.", "gauge", report.Timing.Throughput},
+		{"lepsi_report_p50_latency_ms", "Per-event p50 latency, in milliseconds, in the most recent report.", "gauge", report.Timing.P50LatencyMs},
+		{"lepsi_report_p95_latency_ms", "Per-event p95 latency, in milliseconds, in the most recent report.", "gauge", report.Timing.P95LatencyMs},
+		{"lepsi_report_p99_latency_ms", "Per-event p99 latency, in milliseconds, in the most recent report.", "gauge", report.Timing.P99LatencyMs},
+		{"lepsi_report_max_noise", "Maximum observed noise in the most recent report.", "gauge", report.Noise.MaxNoise},
+		{"lepsi_report_avg_noise", "Average observed noise in the most recent report.", "gauge", report.Noise.AvgNoise},
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", m.name, m.mtype)
+		fmt.Fprintf(&b, "%s %v\n", m.name, m.value)
+	}
+
+	if err := os.WriteFile(r.Path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("prom reporter: write %s: %w", r.Path, err)
+	}
+	return nil
+}
+
+// HTMLReporter writes a self-contained HTML page (no external assets) with
+// an inline SVG bar chart of NoiseMetrics.Distribution and, if
+// TimingBreakdownPath points at a benchmark's timing_breakdown.json, a
+// second bar chart of its phase-time percentages.
+type HTMLReporter struct {
+	Path                string
+	TimingBreakdownPath string
+}
+
+// NewHTMLReporter creates an HTMLReporter that writes to path. Leave
+// timingBreakdownPath empty to omit the phase-time chart.
+func NewHTMLReporter(path, timingBreakdownPath string) *HTMLReporter {
+	return &HTMLReporter{Path: path, TimingBreakdownPath: timingBreakdownPath}
+}
+
+// Write implements Reporter.
+func (r *HTMLReporter) Write(report PSIReport) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>LE-PSI Report</title></head><body>\n")
+	fmt.Fprintf(&b, "<h1>LE-PSI Report — %s</h1>\n", htmlEscape(report.Metadata.Timestamp))
+	fmt.Fprintf(&b, "<p>%d/%d operations succeeded (%.2f%%), %d matches, %d skipped by prefilter.</p>\n",
+		report.Summary.TotalOperations-report.Summary.TotalErrors, report.Summary.TotalOperations,
+		report.Summary.SuccessRate, report.Summary.TotalMatches, report.Summary.SkippedCount)
+
+	b.WriteString("<h2>Noise Distribution</h2>\n")
+	b.WriteString(barChartSVG(report.Noise.Distribution))
+
+	if r.TimingBreakdownPath != "" {
+		if phases, err := readTimingBreakdownPercentages(r.TimingBreakdownPath); err == nil {
+			b.WriteString("<h2>Phase Time Breakdown</h2>\n")
+			b.WriteString(barChartSVG(phases))
+		}
+	}
+
+	b.WriteString("</body></html>\n")
+
+	if err := os.WriteFile(r.Path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("html reporter: write %s: %w", r.Path, err)
+	}
+	return nil
+}
+
+func htmlEscape(s string) string {
+	replacer := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return replacer.Replace(s)
+}
+
+// barChartSVG renders values as a minimal horizontal bar chart in inline
+// SVG, sorted by key so repeated runs render identically.
+func barChartSVG(values map[string]int) string {
+	if len(values) == 0 {
+		return "<p>(no data)</p>\n"
+	}
+	keys := make([]string, 0, len(values))
+	max := 0
+	for k, v := range values {
+		keys = append(keys, k)
+		if v > max {
+			max = v
+		}
+	}
+	sort.Strings(keys)
+	if max == 0 {
+		max = 1
+	}
+
+	const rowHeight = 24
+	const chartWidth = 400
+	height := rowHeight * len(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg width=\"%d\" height=\"%d\" xmlns=\"http://www.w3.org/2000/svg\">\n", chartWidth+150, height)
+	for i, k := range keys {
+		v := values[k]
+		barWidth := int(float64(v) / float64(max) * chartWidth)
+		y := i * rowHeight
+		fmt.Fprintf(&b, "<text x=\"0\" y=\"%d\" font-size=\"12\">%s</text>\n", y+16, htmlEscape(k))
+		fmt.Fprintf(&b, "<rect x=\"150\" y=\"%d\" width=\"%d\" height=\"18\" fill=\"steelblue\"/>\n", y, barWidth)
+		fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" font-size=\"12\">%d</text>\n", 155+barWidth, y+14, v)
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// readTimingBreakdownPercentages extracts the phases.<name>.percentage
+// fields a benchmark's timing_breakdown.json writes, rounding to whole
+// percent for barChartSVG.
+func readTimingBreakdownPercentages(path string) (map[string]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Phases map[string]struct {
+			Percentage float64 `json:"percentage"`
+		} `json:"phases"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	result := make(map[string]int, len(doc.Phases))
+	for name, phase := range doc.Phases {
+		result[name] = int(phase.Percentage + 0.5)
+	}
+	return result, nil
+}
+
+// MultiReporter fans a single report out to several Reporters, continuing
+// on error so one misconfigured destination (e.g. an unwritable path)
+// doesn't prevent the others from receiving the report. If any Reporter
+// fails, Write returns the first error encountered.
+type MultiReporter struct {
+	Reporters []Reporter
+}
+
+// NewMultiReporter creates a MultiReporter over the given reporters.
+func NewMultiReporter(reporters ...Reporter) *MultiReporter {
+	return &MultiReporter{Reporters: reporters}
+}
+
+// Write implements Reporter.
+func (m *MultiReporter) Write(report PSIReport) error {
+	var firstErr error
+	for _, r := range m.Reporters {
+		if err := r.Write(report); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}